@@ -0,0 +1,160 @@
+package ini
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rename records a section or key name changed to ASCII.
+type Rename struct {
+	Old Ref
+	New Ref
+}
+
+// transliterations maps Latin letters with diacritics to ASCII, in pairs of
+// replacement and the letters it replaces.
+var transliterations = func() map[rune]string {
+	pairs := []string{
+		"A", "ÀÁÂÃÄÅĀĂĄ", "a", "àáâãäåāăą", "AE", "Æ", "ae", "æ",
+		"C", "ÇĆĈĊČ", "c", "çćĉċč", "D", "ĎĐÐ", "d", "ďđð",
+		"E", "ÈÉÊËĒĔĖĘĚ", "e", "èéêëēĕėęě", "G", "ĜĞĠĢ", "g", "ĝğġģ",
+		"H", "ĤĦ", "h", "ĥħ", "I", "ÌÍÎÏĨĪĬĮİ", "i", "ìíîïĩīĭįı",
+		"J", "Ĵ", "j", "ĵ", "K", "Ķ", "k", "ķ", "L", "ĹĻĽĿŁ", "l", "ĺļľŀł",
+		"N", "ÑŃŅŇ", "n", "ñńņň", "O", "ÒÓÔÕÖØŌŎŐ", "o", "òóôõöøōŏő",
+		"OE", "Œ", "oe", "œ", "R", "ŔŖŘ", "r", "ŕŗř", "S", "ŚŜŞŠ", "s", "śŝşš", "ss", "ß",
+		"T", "ŢŤŦ", "t", "ţťŧ", "TH", "Þ", "th", "þ", "U", "ÙÚÛÜŨŪŬŮŰŲ", "u", "ùúûüũūŭůűų",
+		"W", "Ŵ", "w", "ŵ", "Y", "ÝŶŸ", "y", "ýÿŷ", "Z", "ŹŻŽ", "z", "źżž",
+	}
+	m := make(map[rune]string)
+	for i := 0; i < len(pairs); i += 2 {
+		for _, r := range pairs[i+1] {
+			m[r] = pairs[i]
+		}
+	}
+	return m
+}()
+
+// isASCII returns true if s contains only ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// Transliterate returns given name in ASCII, Latin letters with diacritics are
+// replaced by their base letters, e.g. "Größe" => "Grosse", and other non-ASCII
+// characters are replaced by '_'.
+func Transliterate(name string) string {
+	if isASCII(name) {
+		return name
+	}
+
+	var b strings.Builder
+	b.Grow(len(name))
+	for _, r := range name {
+		switch {
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case len(transliterations[r]) > 0:
+			b.WriteString(transliterations[r])
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// Renames returns section and key names changed by ASCIITransliterate
+// while parsing, in order of parsing.
+func (m *Manager) Renames() []Rename {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return slices.Clone(m.renames)
+}
+
+// asciiRef is like transliterateRef but it records the rename, see Renames.
+func (m *Manager) asciiRef(ref Ref) (Ref, error) {
+	renamed, err := m.transliterateRef(ref)
+	if err != nil || renamed == ref {
+		return renamed, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.renames = append(m.renames, Rename{Old: ref, New: renamed})
+	return renamed, nil
+}
+
+// transliterateRef applies Options.ASCIINames to the name referred by given ref,
+// i.e. the key name or the section name if key name is empty.
+func (m *Manager) transliterateRef(ref Ref) (Ref, error) {
+	name := ref.Key
+	if len(name) == 0 {
+		name = ref.Section
+	}
+	if m.options.ASCIINames == ASCIIPass || isASCII(name) {
+		return ref, nil
+	}
+	if m.options.ASCIINames == ASCIIReject {
+		return ref, fmt.Errorf("name %q contains non-ASCII characters", name)
+	}
+
+	renamed := ref
+	if len(ref.Key) > 0 {
+		renamed.Key = Transliterate(name)
+	} else {
+		renamed.Section = Transliterate(name)
+	}
+	return renamed, nil
+}
+
+// TransliterateNames renames all sections and keys with non-ASCII names by
+// Transliterate and returns what changed. Names are left unchanged when the
+// transliterated name is already taken.
+func (m *Manager) TransliterateNames() []Rename {
+	if m.rejectFrozen() {
+		return nil
+	}
+
+	m.materialize()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var renames []Rename
+	for i, name := range m.sectionList {
+		sec := m.sections[name]
+		if to := Transliterate(name); to != name {
+			if _, ok := m.sections[to]; !ok {
+				delete(m.sections, name)
+				m.sections[to] = sec
+				m.sectionList[i] = to
+				sec.name, sec.display = to, ""
+				m.dirty = true
+				renames = append(renames, Rename{Old: Ref{Section: name}, New: Ref{Section: to}})
+			}
+		}
+
+		for j, kname := range sec.keyList {
+			to := Transliterate(kname)
+			if _, ok := sec.keys[to]; to == kname || ok {
+				continue
+			}
+			key := sec.keys[kname]
+			delete(sec.keys, kname)
+			sec.keys[to] = key
+			sec.keyList[j] = to
+			key.name, key.display = to, ""
+			sec.dirty = true
+			renames = append(renames, Rename{
+				Old: Ref{Section: sec.name, Key: kname},
+				New: Ref{Section: sec.name, Key: to},
+			})
+		}
+	}
+	return renames
+}
@@ -0,0 +1,98 @@
+package ini
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Name of the default profile and prefix of other profiles in AWS config files.
+const (
+	defaultProfile = "default"
+	profilePrefix  = "profile "
+)
+
+// Profile returns a merged view of the named profile of an AWS-style config or
+// credentials file, i.e. section [profile name] or [name], over the default
+// profile [default]. Keys of the profile take precedence over keys of the default
+// profile. The view is a detached copy, changes to it are not written back.
+func (m *Manager) Profile(name string) (*Section, error) {
+	sec, ok := m.profileSection(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: profile %q", ErrSectionNotFound, name)
+	}
+
+	var def *Section
+	if name != defaultProfile {
+		def, _ = m.profileSection(defaultProfile)
+	}
+
+	view := newSection(m, sec.name)
+	defer m.readUnlock(m.readLock())
+	view.display, view.Comment = sec.display, sec.Comment
+	view.source, view.line = sec.source, sec.line
+	if def != nil {
+		copyProfileKeys(view, def)
+	}
+	copyProfileKeys(view, sec)
+	return view, nil
+}
+
+// ProfileNames returns names of profiles of an AWS-style config or credentials
+// file in order of definition, see Profile. Profiles are the default section
+// [default], sections [profile name] of config files and sections [name] of
+// credentials files, other sections like [sso-session name] and [services name]
+// are skipped.
+func (m *Manager) ProfileNames() []string {
+	var names []string
+	for sec := range m.All() {
+		name := sec.Name()
+		if trimmed, ok := strings.CutPrefix(name, profilePrefix); ok {
+			name = strings.TrimSpace(trimmed)
+		}
+		if !isProfileName(name) || slices.Contains(names, name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// isProfileName reports whether name is a valid profile name, i.e. non-empty
+// without whitespace.
+func isProfileName(name string) bool {
+	return len(name) > 0 && !strings.ContainsFunc(name, unicode.IsSpace)
+}
+
+// profileSection returns section of the named profile, [profile name] of config
+// files takes precedence over [name] of credentials files.
+func (m *Manager) profileSection(name string) (*Section, bool) {
+	if !isProfileName(name) {
+		return nil, false
+	}
+	if sec, err := m.GetSection(profilePrefix + name); err == nil {
+		return sec, true
+	}
+	if sec, err := m.GetSection(name); err == nil {
+		return sec, true
+	}
+	return nil, false
+}
+
+// copyProfileKeys copies keys of src into dst, existing keys of dst are replaced.
+// The caller must hold the read lock.
+func copyProfileKeys(dst, src *Section) {
+	for _, name := range src.keyList {
+		sk := src.keys[name]
+		dk := newKey(dst, name, sk.rawValue())
+		dk.display, dk.Comment = sk.display, sk.Comment
+		dk.isBooleanType = sk.isBooleanType
+		dk.nestedValues = slices.Clone(sk.nestedValues)
+		dk.source, dk.line = sk.source, sk.line
+		if _, ok := dst.keys[name]; !ok {
+			dst.keyList = append(dst.keyList, name)
+		}
+		dst.keys[name] = dk
+	}
+}
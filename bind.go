@@ -0,0 +1,119 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync/atomic"
+)
+
+// MapTo maps keys of the default section to given struct pointer like Section.MapTo,
+// fields of struct type or pointer to struct type are mapped from the section named
+// by the "ini" tag or the field name, e.g. field DB of struct type is mapped from [DB].
+func (m *Manager) MapTo(v any) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("ini: cannot map to non-pointer struct")
+	}
+	val = val.Elem()
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || !isSectionType(field.Type) {
+			continue
+		}
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		sec, err := m.GetSection(name)
+		if err != nil {
+			continue
+		}
+		fv := val.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				fv.Set(reflect.New(field.Type.Elem()))
+			}
+		} else {
+			fv = fv.Addr()
+		}
+		if err = sec.MapTo(fv.Interface()); err != nil {
+			return fmt.Errorf("ini: error mapping section %q: %w", name, err)
+		}
+	}
+	return m.Section("").MapTo(v)
+}
+
+// isSectionType returns true if given field type is mapped from a section by Manager.MapTo.
+func isSectionType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && typ != reflectTime
+}
+
+// Bind maps data to given struct pointer by MapTo. After every Append and Reload,
+// data is mapped to a new value of the same type, which is passed to fn along with
+// the error of mapping, e.g. to replace the struct while holding a lock of its readers.
+// Without fn, the new value is copied into the struct when mapping succeeds, which
+// is only safe when nothing reads the struct concurrently. See BindAtomic for
+// lock-free readers.
+func (m *Manager) Bind(ptr any, fn ...func(v any, err error)) error {
+	if err := m.MapTo(ptr); err != nil {
+		return err
+	}
+	typ := reflect.TypeOf(ptr).Elem()
+	m.addBinding(func() {
+		v := reflect.New(typ)
+		err := m.MapTo(v.Interface())
+		if len(fn) == 0 {
+			if err == nil {
+				reflect.ValueOf(ptr).Elem().Set(v.Elem())
+			}
+			return
+		}
+		for _, f := range fn {
+			f(v.Interface(), err)
+		}
+	})
+	return nil
+}
+
+// BindAtomic maps data to a new value of T stored into p, and stores a newly mapped
+// value after every Append and Reload. The previous value is kept when mapping fails.
+func BindAtomic[T any](m *Manager, p *atomic.Pointer[T]) error {
+	bind := func() error {
+		v := new(T)
+		if err := m.MapTo(v); err != nil {
+			return err
+		}
+		p.Store(v)
+		return nil
+	}
+	if err := bind(); err != nil {
+		return err
+	}
+	m.addBinding(func() { _ = bind() })
+	return nil
+}
+
+// addBinding registers a function called after every Append and Reload.
+func (m *Manager) addBinding(fn func()) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.bindings = append(m.bindings, fn)
+}
+
+// rebind calls all registered bindings.
+func (m *Manager) rebind() {
+	m.mutex.RLock()
+	bindings := slices.Clone(m.bindings)
+	m.mutex.RUnlock()
+
+	for _, fn := range bindings {
+		fn()
+	}
+}
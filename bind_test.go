@@ -0,0 +1,40 @@
+package ini
+
+import "testing"
+
+func TestBindMapsFreshValue(t *testing.T) {
+	type config struct {
+		Name string `ini:"name"`
+	}
+	m, err := LoadSources(Options{DuplicateKeys: DuplicateKeepLast}, map[string]string{"name": "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var bound, copied config
+	var got *config
+	if err = m.Bind(&bound, func(v any, err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		got = v.(*config)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Bind(&copied); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = m.Append(map[string]string{"name": "second"}); err != nil {
+		t.Fatal(err)
+	}
+	if bound.Name != "first" {
+		t.Errorf("bound struct was changed to %q", bound.Name)
+	}
+	if got == nil || got.Name != "second" {
+		t.Errorf("callback got %+v, want second", got)
+	}
+	if copied.Name != "second" {
+		t.Errorf("copied struct = %q, want second", copied.Name)
+	}
+}
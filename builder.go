@@ -0,0 +1,49 @@
+package ini
+
+// Builder constructs a Manager programmatically, e.g.
+//
+//	m := ini.Build().Section("db").Key("host", "localhost").Comment("database host").Done()
+type Builder struct {
+	m   *Manager
+	sec *Section
+	key *Key
+}
+
+// Build returns a builder of a new manager, options are optional.
+// Keys are added to the default section until Section is called.
+func Build(opts ...Options) *Builder {
+	m := Empty(opts...)
+	return &Builder{m: m, sec: m.NewSection("")}
+}
+
+// Section switches to the section with given name, it is created if not exists.
+func (b *Builder) Section(name string) *Builder {
+	b.sec, b.key = b.m.NewSection(name), nil
+	return b
+}
+
+// Key adds a key with given value to the current section, the value
+// of an existing key is replaced.
+func (b *Builder) Key(name, value string) *Builder {
+	b.key = b.sec.NewKey(name, value)
+	if b.key.Value() != value {
+		b.key.SetValue(value)
+	}
+	return b
+}
+
+// Comment sets comment of the last added key, or of the current section
+// when no key was added since Section was called.
+func (b *Builder) Comment(comment string) *Builder {
+	if b.key != nil {
+		b.key.SetComment(comment)
+	} else {
+		b.sec.SetComment(comment)
+	}
+	return b
+}
+
+// Done returns the built manager.
+func (b *Builder) Done() *Manager {
+	return b.m
+}
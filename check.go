@@ -0,0 +1,88 @@
+package ini
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Problem is a malformed line found by Check.
+type Problem struct {
+	// Line and Column are the 1-based position of the problem, Line is zero
+	// when the data could not be read.
+	Line   int
+	Column int
+	// Text is the raw text of the line, excluding line break.
+	Text string
+	// Err is the cause of the problem, e.g. ErrEmptyKeyName.
+	Err error
+}
+
+// String returns the cause prefixed by the position.
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Err.Error()
+	}
+	return fmt.Sprintf("%d:%d: %v", p.Line, p.Column, p.Err)
+}
+
+// Check validates syntax of data from given reader with given dialect options and
+// returns all problems found in order, e.g. unclosed sections, empty key names,
+// missing closing quotes and duplicate keys unless Options.DuplicateKeys is DuplicateShadow.
+// The data is scanned like Scan does, nothing is returned for valid data.
+func Check(r io.Reader, opts Options) []Problem {
+	opts.ContinueOnError = true
+	foldSection := opts.Insensitive || opts.InsensitiveSections
+	foldKey := opts.Insensitive || opts.InsensitiveKeys
+
+	// Keys defined so far by section, duplicates are recorded along with syntax errors.
+	var problems []Problem
+	seen := make(map[string]map[string]bool)
+	err := Scan(r, opts, func(ev Event) error {
+		if ev.Type != EventScanKey || opts.DuplicateKeys == DuplicateShadow {
+			return nil
+		}
+		section, key := ev.Section, ev.Key
+		if foldSection {
+			section = strings.ToLower(section)
+		}
+		if foldKey {
+			key = strings.ToLower(key)
+		}
+		keys := seen[section]
+		if keys == nil {
+			keys = make(map[string]bool)
+			seen[section] = keys
+		}
+		if keys[key] && !ev.repeatable {
+			problems = append(problems, Problem{
+				Line:   ev.Line,
+				Column: ev.column,
+				Text:   string(bytes.TrimRight(ev.text, "\r\n")),
+				Err:    fmt.Errorf("duplicate key %q in section %q", ev.Key, section),
+			})
+		}
+		keys[key] = true
+		return nil
+	})
+
+	// Errors of lines are joined, anything else stopped the scan.
+	joined, ok := err.(interface{ Unwrap() []error })
+	if err != nil && !ok {
+		return append(problems, Problem{Err: err})
+	}
+	if ok {
+		for _, err := range joined.Unwrap() {
+			var pe *ParseError
+			if errors.As(err, &pe) {
+				problems = append(problems, Problem{Line: pe.Line, Column: pe.Column, Text: pe.Text, Err: pe.Err})
+			}
+		}
+	}
+	slices.SortStableFunc(problems, func(a, b Problem) int { return cmp.Compare(a.Line, b.Line) })
+	return problems
+}
@@ -0,0 +1,27 @@
+package ini
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	data := "[s]\na = 1\n  A = 2\n[broken\nb[] = 1\nb[] = 2\n = empty\n"
+	problems := Check(strings.NewReader(data), Options{InsensitiveKeys: true, AllowArrayKeys: true})
+	want := []string{
+		`3:3: duplicate key "A" in section "s"`,
+		"4:1: ",
+		"7:2: ",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("problems %q, want %d", problems, len(want))
+	}
+	for i, p := range problems {
+		if !strings.HasPrefix(p.String(), want[i]) {
+			t.Errorf("problem %d = %q, want prefix %q", i, p.String(), want[i])
+		}
+	}
+	if problems[0].Text != "  A = 2" {
+		t.Errorf("text of duplicate = %q", problems[0].Text)
+	}
+}
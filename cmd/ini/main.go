@@ -0,0 +1,227 @@
+// Command ini reads, edits, validates and converts INI files.
+//
+// Usage:
+//
+//	ini get FILE SECTION.KEY
+//	ini set FILE SECTION.KEY VALUE
+//	ini del FILE SECTION[.KEY]
+//	ini lint FILE...
+//	ini convert [-to json|yaml|toml] FILE
+//	ini merge [-policy overwrite|keep-existing|error|append-shadow] FILE...
+//
+// Paths are resolved like ini.Manager.Get: the longest section name holding
+// the key is tried first, and new keys are added to the section named by the
+// part before the last dot, or to the default section when there is no dot.
+// Commands that modify a file write it back in place, other commands write
+// to standard output.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go-slim.dev/ini"
+)
+
+const usage = `usage: ini <command> [arguments]
+
+commands:
+  get FILE SECTION.KEY           print value of a key
+  set FILE SECTION.KEY VALUE     set value of a key and save the file
+  del FILE SECTION[.KEY]         delete a key or a section and save the file
+  lint FILE...                   report syntax problems
+  convert [-to FORMAT] FILE      print the file as json, yaml or toml
+  merge [-policy POLICY] FILE... print the files merged in order
+`
+
+// errUsage is returned when a command is called with wrong arguments.
+var errUsage = errors.New("wrong arguments")
+
+// errProblems is returned by lint when any problem was reported.
+var errProblems = errors.New("problems found")
+
+var commands = map[string]func(args []string, stdout io.Writer) error{
+	"get":     runGet,
+	"set":     runSet,
+	"del":     runDel,
+	"lint":    runLint,
+	"convert": runConvert,
+	"merge":   runMerge,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command given by args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "ini: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+
+	switch err := cmd(args[1:], stdout); {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		fmt.Fprint(stderr, usage)
+		return 2
+	case errors.Is(err, errProblems):
+		return 1
+	default:
+		fmt.Fprintf(stderr, "ini %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+func runGet(args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	m, err := ini.Load(args[0])
+	if err != nil {
+		return err
+	}
+	val, ok := m.Value(args[1])
+	if !ok {
+		return fmt.Errorf("%s: %w", args[1], ini.ErrKeyNotFound)
+	}
+	_, err = fmt.Fprintln(stdout, val)
+	return err
+}
+
+func runSet(args []string, _ io.Writer) error {
+	if len(args) != 3 {
+		return errUsage
+	}
+	m, err := ini.Load(args[0])
+	if err != nil {
+		return err
+	}
+	section, name := m.SplitPath(args[1])
+	if name == "" {
+		return fmt.Errorf("%s: %w", args[1], ini.ErrEmptyKeyName)
+	}
+	m.NewSection(section).NewKey(name, args[2]).SetValue(args[2])
+	return m.SaveTo(args[0])
+}
+
+func runDel(args []string, _ io.Writer) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	m, err := ini.Load(args[0])
+	if err != nil {
+		return err
+	}
+	if !m.DeleteSection(args[1]) {
+		section, name := m.SplitPath(args[1])
+		sec, err := m.GetSection(section)
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[1], err)
+		}
+		if !sec.DeleteKey(name) {
+			return fmt.Errorf("%s: %w", args[1], ini.ErrKeyNotFound)
+		}
+	}
+	return m.SaveTo(args[0])
+}
+
+func runLint(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	found := false
+	for _, filename := range args {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		problems := ini.Check(f, ini.Options{})
+		f.Close()
+		for _, p := range problems {
+			if p.Line == 0 {
+				fmt.Fprintf(stdout, "%s: %v\n", filename, p.Err)
+			} else {
+				fmt.Fprintf(stdout, "%s:%s\n", filename, p)
+			}
+		}
+		found = found || len(problems) > 0
+	}
+	if found {
+		return errProblems
+	}
+	return nil
+}
+
+func runConvert(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	to := fs.String("to", "json", "output format: json, yaml or toml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+	m, err := ini.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch *to {
+	case "json":
+		if data, err = m.MarshalJSON(); err == nil {
+			data = append(data, '\n')
+		}
+	case "yaml":
+		data, err = m.ToYAML()
+	case "toml":
+		data, err = m.ToTOML()
+	default:
+		return fmt.Errorf("unsupported format %q", *to)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(data)
+	return err
+}
+
+func runMerge(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	policy := ini.MergeOverwrite
+	fs.TextVar(&policy, "policy", ini.MergeOverwrite, "policy for existing keys")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+
+	m, err := ini.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	for _, filename := range fs.Args()[1:] {
+		other, err := ini.Load(filename)
+		if err != nil {
+			return err
+		}
+		if err = m.Merge(other, policy); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	_, err = m.WriteTo(stdout)
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSmoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	data := "name = app\n\n[server]\nport = 80\nhost.name = localhost\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		code int
+		out  string
+	}{
+		{[]string{"get", path, "name"}, 0, "app\n"},
+		{[]string{"get", path, "server.port"}, 0, "80\n"},
+		{[]string{"get", path, "server.host.name"}, 0, "localhost\n"},
+		{[]string{"set", path, "server.host.name", "example.com"}, 0, ""},
+		{[]string{"get", path, "server.host.name"}, 0, "example.com\n"},
+		{[]string{"set", path, "server.tls.cert", "a.pem"}, 0, ""},
+		{[]string{"get", path, "server.tls.cert"}, 0, "a.pem\n"},
+		{[]string{"del", path, "server.host.name"}, 0, ""},
+		{[]string{"get", path, "server.host.name"}, 1, ""},
+		{[]string{"del", path, "server.tls"}, 0, ""},
+		{[]string{"get", path, "server.tls.cert"}, 1, ""},
+		{[]string{"del", path, "server.missing"}, 1, ""},
+		{[]string{"get", path}, 2, ""},
+	} {
+		cmd := tt.args[0] + " " + strings.Join(tt.args[2:], " ")
+		var stdout, stderr bytes.Buffer
+		if code := run(tt.args, &stdout, &stderr); code != tt.code {
+			t.Errorf("%s: exit code %d, want %d: %s", cmd, code, tt.code, stderr.String())
+		}
+		if got := stdout.String(); got != tt.out {
+			t.Errorf("%s: output %q, want %q", cmd, got, tt.out)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "name = app\n\n[server]\nport = 80\n"; string(got) != want {
+		t.Errorf("saved file:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package ini
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// computed is a virtual key whose raw value is synthesized by a function, see Manager.Compute.
+type computed struct {
+	fn func(s *Section) string
+
+	mu    sync.Mutex
+	gen   int64 // generation of the cached value, zero if none
+	value string
+}
+
+// Compute registers a virtual key of given dotted path, as in Get, whose raw value is
+// synthesized by fn on demand, e.g. a DSN derived from host, port and user keys of the
+// section given to fn. The value is interpolated like parsed values and cached until
+// any key or section is added, changed or deleted, or data sources are reloaded.
+// Computed keys are found by GetKey, Key, Get and interpolation, they are never written
+// and keys defined in data sources take precedence. fn must not read the computed key itself.
+func (m *Manager) Compute(path string, fn func(s *Section) string) {
+	if m.rejectFrozen() {
+		return
+	}
+
+	ref := m.splitPath(path)
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.computed == nil {
+		m.computed = make(map[Ref]*computed)
+		// Any change may affect computed values.
+		m.events.Subscribe(func(ev Event) {
+			switch ev.Type {
+			case EventSourceAppended, EventSourceReloaded, EventSectionAdded, EventKeyChanged, EventKeyAdded:
+				m.computeGen.Add(1)
+			}
+		})
+		m.computeGen.Store(1)
+	}
+	m.computed[ref] = &computed{fn: fn}
+}
+
+// invalidateComputed drops cached computed values on changes which emit no event,
+// e.g. deleting keys and sections.
+func (m *Manager) invalidateComputed() {
+	m.computeGen.Add(1)
+}
+
+// computedKey returns the computed key of given folded name bound to given section.
+func (m *Manager) computedKey(s *Section, name string) (*Key, bool) {
+	locked := m.readLock()
+	c := m.computed[Ref{Section: s.name, Key: name}]
+	m.readUnlock(locked)
+	if c == nil {
+		return nil, false
+	}
+
+	key := newKey(s, name, "")
+	key.computed = c
+	return key, true
+}
+
+// get returns the cached value, or synthesizes it for given section.
+func (c *computed) get(s *Section, gen *atomic.Int64) string {
+	g := gen.Load()
+	c.mu.Lock()
+	if c.gen == g {
+		defer c.mu.Unlock()
+		return c.value
+	}
+	c.mu.Unlock()
+
+	// The function is called without holding the lock since it may read other computed keys.
+	value := c.fn(s)
+	c.mu.Lock()
+	c.gen, c.value = g, value
+	c.mu.Unlock()
+	return value
+}
@@ -0,0 +1,138 @@
+package ini
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// loader returns a function loading the i-th of given data sources into the manager.
+// When Options.ConcurrentSources allows, all sources are parsed up front in parallel
+// into staging managers, which are merged in order by the returned function.
+func (m *Manager) loader(sources []*dataSource) func(i int) error {
+	n := min(m.options.ConcurrentSources, len(sources))
+	if n < 2 {
+		return func(i int) error { return sources[i].reload(m) }
+	}
+
+	stages := make([]*Manager, len(sources))
+	errs := make([]error, len(sources))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, s := range sources {
+		stages[i] = m.stage()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = s.reload(stages[i])
+		}()
+	}
+	wg.Wait()
+
+	return func(i int) error {
+		// Keep what was parsed before the error like sequential parsing does.
+		if err := m.absorb(stages[i]); err != nil {
+			return err
+		}
+		stages[i] = nil
+		return errs[i]
+	}
+}
+
+// stagedDefinition is a key definition recorded by a staging manager.
+type stagedDefinition struct {
+	section string
+	definition
+}
+
+// stage returns an empty manager with the same options to parse a data source in isolation.
+// It records definitions of keys to apply them with the rules of the manager, see absorb.
+func (m *Manager) stage() *Manager {
+	opts := m.options
+	opts.Mutex = nil
+	// Values are spilled when definitions are applied.
+	opts.SpillThreshold = 0
+	st := New(opts)
+	st.stats = m.stats
+	st.staging = true
+	return st
+}
+
+// record records given key definition of a section when the manager is staging.
+func (m *Manager) record(section string, d *definition) {
+	if !m.staging {
+		return
+	}
+	m.mutex.Lock()
+	m.staged = append(m.staged, stagedDefinition{section: section, definition: *d})
+	m.mutex.Unlock()
+}
+
+// absorb merges parsed sections and keys of given staging manager into the manager
+// as if its data source was parsed directly by the manager: recorded definitions
+// are applied in order by Section.define like the parser does.
+func (m *Manager) absorb(st *Manager) error {
+	// Lazily indexed sections record their definitions when materialized.
+	sections := make(map[string]*Section)
+	for src := range st.All() {
+		src.materialize()
+		dst := m.addSection(src.Name(), src.implicit)
+		sections[src.name] = dst
+		if src.line > 0 {
+			m.mutex.Lock()
+			dst.setPosition(src.source, src.line)
+			dst.Comment = src.Comment
+			m.mutex.Unlock()
+		}
+	}
+
+	st.mutex.Lock()
+	staged, positions, warnings := st.staged, st.positions, st.warnings
+	st.staged = nil
+	st.mutex.Unlock()
+
+	var last *Key
+	var errs []*ParseError
+	for i := range staged {
+		d := &staged[i]
+		if d.nested && last == nil {
+			// Nested values of an ignored or rejected key are skipped as well.
+			continue
+		}
+		key, err := sections[d.section].define(&d.definition)
+		if errors.Is(err, errDuplicateKey) {
+			pe := &ParseError{Source: d.source, Line: d.line, Err: err}
+			if !m.options.ContinueOnError {
+				return pe
+			}
+			m.log(slog.LevelWarn, "ini: skipped malformed line", slog.String("source", pe.Source),
+				slog.Int("line", pe.Line), errorAttr(pe.Err))
+			errs = append(errs, pe)
+			last = nil
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !d.nested {
+			last = key
+		}
+	}
+
+	m.mutex.Lock()
+	if len(m.newline) == 0 {
+		m.newline = st.newline
+	}
+	if m.encoding == nil {
+		m.encoding = st.encoding
+	}
+	m.positions = append(m.positions, positions...)
+	m.warnings = append(m.warnings, warnings...)
+	m.warnings = append(m.warnings, errs...)
+	m.mutex.Unlock()
+	if st.contributed.Load() {
+		m.contributed.Store(true)
+	}
+	return nil
+}
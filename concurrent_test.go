@@ -0,0 +1,59 @@
+package ini
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestConcurrentSources(t *testing.T) {
+	sources := func() []any {
+		return []any{
+			AsLayer("user", LayerUser, []byte("[s]\na = 1\nc = x\nflag\n")),
+			[]byte("[s]\na += 2\nb[] = 1\nb[] = 2\nc = y\n- = first\nn =\n  nested\n"),
+			AsLayer("defaults", LayerDefaults, []byte("[s]\na[] = 3\nb[] = 3\nc += z\nflag\n- = second\n")),
+			map[string]map[string]string{"s": {"c": "map", "d": "map"}},
+			[]byte("[s]\nb += 4\nd = last\nd = again\n[t]\nk = v\n"),
+		}
+	}
+	for name, opts := range map[string]Options{
+		"default":  {},
+		"extended": {AllowArrayKeys: true, AllowAppendOperator: true, AllowBooleanKeys: true, AllowNestedValues: true},
+		"last":     {AllowArrayKeys: true, AllowAppendOperator: true, DuplicateKeys: DuplicateKeepLast},
+		"shadow":   {AllowArrayKeys: true, AllowAppendOperator: true, DuplicateKeys: DuplicateShadow},
+		"continue": {AllowArrayKeys: true, DuplicateKeys: DuplicateError, ContinueOnError: true},
+		"error":    {DuplicateKeys: DuplicateError},
+	} {
+		want, wantErr := LoadSources(opts, sources()...)
+		opts.ConcurrentSources = 4
+		got, gotErr := LoadSources(opts, sources()...)
+		if fmt.Sprint(wantErr) != fmt.Sprint(gotErr) {
+			t.Errorf("%s: error %v, want %v", name, gotErr, wantErr)
+			continue
+		}
+		if wantErr != nil {
+			continue
+		}
+
+		var wb, gb bytes.Buffer
+		if _, err := want.WriteTo(&wb); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := got.WriteTo(&gb); err != nil {
+			t.Fatal(err)
+		}
+		if wb.String() != gb.String() {
+			t.Errorf("%s:\nwant:\n%s\ngot:\n%s", name, wb.String(), gb.String())
+		}
+		for _, key := range want.Section("s").Keys() {
+			wl, _ := want.Origin("s", key.Name())
+			gl, _ := got.Origin("s", key.Name())
+			if wl != gl {
+				t.Errorf("%s: origin of %s = %v, want %v", name, key.Name(), gl, wl)
+			}
+		}
+		if w, g := len(want.ParseWarnings()), len(got.ParseWarnings()); w != g {
+			t.Errorf("%s: %d warnings, want %d", name, g, w)
+		}
+	}
+}
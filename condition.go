@@ -0,0 +1,52 @@
+package ini
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ErrConditional is returned when saving a manager loaded with Options.Condition,
+// since sections excluded by conditions are lost and saving would delete them.
+var ErrConditional = errors.New("ini: cannot save conditionally loaded data")
+
+// splitCondition splits a conditional section name into the name and condition,
+// i.e. "name if cond" or "name @tag". Condition is empty for other names.
+func splitCondition(name string) (string, string) {
+	if i := strings.Index(name, " if "); i > -1 {
+		return strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+4:])
+	}
+	if i := strings.LastIndex(name, " @"); i > -1 {
+		return strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+1:])
+	}
+	return name, ""
+}
+
+// ConditionContext returns a condition for Options.Condition evaluated against given
+// variables and tags, e.g. {"GOOS": runtime.GOOS} and "production". It accepts
+// "name=value", "name!=value" and "@tag", joined by "," when all of them must hold.
+// Unknown variables have empty values, and malformed conditions do not hold.
+func ConditionContext(vars map[string]string, tags ...string) func(cond string) bool {
+	return func(cond string) bool {
+		for term := range strings.SplitSeq(cond, ",") {
+			if !evalCondition(strings.TrimSpace(term), vars, tags) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// evalCondition evaluates a single term of a condition, see ConditionContext.
+func evalCondition(term string, vars map[string]string, tags []string) bool {
+	if tag, ok := strings.CutPrefix(term, "@"); ok {
+		return slices.Contains(tags, tag)
+	}
+	if name, value, ok := strings.Cut(term, "!="); ok {
+		return vars[strings.TrimSpace(name)] != strings.TrimSpace(value)
+	}
+	if name, value, ok := strings.Cut(term, "="); ok {
+		return vars[strings.TrimSpace(name)] == strings.TrimSpace(value)
+	}
+	return false
+}
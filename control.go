@@ -0,0 +1,34 @@
+package ini
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isControlChar returns true for ASCII control characters except tab and line breaks.
+func isControlChar(c byte) bool {
+	return (c < 0x20 && c != '\t' && c != '\n' && c != '\r') || c == 0x7f
+}
+
+// applyControlCharPolicy handles control characters in given value by policy.
+func applyControlCharPolicy(val string, policy ControlCharPolicy) (string, error) {
+	if policy == ControlCharPass || strings.IndexFunc(val, func(r rune) bool {
+		return r < 0x80 && isControlChar(byte(r))
+	}) < 0 {
+		return val, nil
+	}
+	if policy == ControlCharReject {
+		return "", fmt.Errorf("value %q contains control characters", val)
+	}
+
+	var b strings.Builder
+	b.Grow(len(val) + 8)
+	for i := 0; i < len(val); i++ {
+		if c := val[i]; isControlChar(c) {
+			fmt.Fprintf(&b, "\\x%02x", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
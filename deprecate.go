@@ -0,0 +1,71 @@
+package ini
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// deprecation is a key replaced by another key, see Manager.Deprecate.
+type deprecation struct {
+	to     Ref
+	msg    string
+	warned atomic.Bool
+}
+
+// Deprecate registers key of old path as replaced by key of new path, paths are
+// dotted as in Get. Reads of the old key resolve to the new key if it exists, the
+// old key is returned otherwise. A warning with given message is logged through
+// Options.Logger on the first read of the old key.
+func (m *Manager) Deprecate(old, new, msg string) {
+	if m.rejectFrozen() {
+		return
+	}
+
+	from, to := m.splitPath(old), m.splitPath(new)
+	if from == to {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.deprecations == nil {
+		m.deprecations = make(map[Ref]*deprecation)
+	}
+	m.deprecations[from] = &deprecation{to: to, msg: msg}
+}
+
+// splitPath returns reference to the key of given dotted path with folded names,
+// the path is resolved like Get does, see SplitPath.
+func (m *Manager) splitPath(path string) Ref {
+	sec, name := m.SplitPath(path)
+	return Ref{Section: m.foldSection(sec), Key: m.foldKey(name)}
+}
+
+// replacement returns the key replacing deprecated key of given folded names.
+func (m *Manager) replacement(section, name string) (*Key, bool) {
+	locked := m.readLock()
+	d := m.deprecations[Ref{Section: section, Key: name}]
+	m.readUnlock(locked)
+	if d == nil {
+		return nil, false
+	}
+
+	if !d.warned.Swap(true) {
+		replacement := d.to.Key
+		if len(d.to.Section) > 0 {
+			replacement = d.to.Section + m.options.ChildSectionDelimiter + replacement
+		}
+		m.log(slog.LevelWarn, "ini: deprecated key", slog.String("section", section), slog.String("key", name),
+			slog.String("replacement", replacement), slog.String("message", d.msg))
+	}
+	sec, err := m.GetSection(d.to.Section)
+	if err != nil {
+		return nil, false
+	}
+	key, err := sec.GetKeyLocal(d.to.Key)
+	if err != nil {
+		return nil, false
+	}
+	return key, true
+}
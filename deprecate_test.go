@@ -0,0 +1,21 @@
+package ini
+
+import "testing"
+
+func TestDeprecateDottedKey(t *testing.T) {
+	m, err := LoadSources(Options{}, []byte("[a]\nb.c = old\n[x]\ny = new\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if section, name := m.SplitPath("a.b.c"); section != "a" || name != "b.c" {
+		t.Errorf("SplitPath = %q, %q, want a, b.c", section, name)
+	}
+	if section, name := m.SplitPath("a.b.d"); section != "a.b" || name != "d" {
+		t.Errorf("SplitPath of missing key = %q, %q, want a.b, d", section, name)
+	}
+
+	m.Deprecate("a.b.c", "x.y", "renamed")
+	if got := m.Get("a.b.c").String(); got != "new" {
+		t.Errorf("a.b.c = %q, want new", got)
+	}
+}
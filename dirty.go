@@ -0,0 +1,37 @@
+package ini
+
+// IsDirty returns true if any section or key was modified since data sources
+// were loaded or data was saved last time.
+func (m *Manager) IsDirty() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.dirty {
+		return true
+	}
+	for _, sec := range m.sections {
+		if sec.dirty {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDirty returns true if the section or any of its keys was modified since
+// data sources were loaded or data was saved last time.
+func (s *Section) IsDirty() bool {
+	s.m.mutex.RLock()
+	defer s.m.mutex.RUnlock()
+	return s.dirty
+}
+
+// markClean resets modification flags of manager and all sections.
+func (m *Manager) markClean() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.dirty = false
+	for _, sec := range m.sections {
+		sec.dirty = false
+	}
+}
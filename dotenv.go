@@ -0,0 +1,111 @@
+package ini
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	dotEnvUnescaper = strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`, `\$`, `$`)
+	dotEnvEscaper   = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`, `"`, `\"`, `\`, `\\`, `$`, `\$`)
+)
+
+// trimExport removes the leading "export" keyword of a dotenv line.
+func trimExport(line []byte) []byte {
+	if rest, ok := bytes.CutPrefix(line, []byte("export")); ok && len(rest) > 0 && (rest[0] == ' ' || rest[0] == '\t') {
+		return bytes.TrimLeft(rest, " \t")
+	}
+	return line
+}
+
+// readDotEnvValue reads a dotenv value. Double-quoted values may span multiple lines
+// and support escapes, single-quoted values are taken literally, and unquoted values
+// end at an inline comment starting with " #".
+func (p *parser) readDotEnvValue(in []byte) (string, error) {
+	val := strings.TrimSpace(string(in))
+	if len(val) == 0 {
+		return "", nil
+	}
+
+	switch quote := val[0]; quote {
+	case '"', '\'':
+		val = val[1:]
+		for {
+			if end := closingQuote(val, quote); end > -1 {
+				if i := strings.IndexByte(val[end+1:], '#'); i > -1 {
+					p.comment.WriteString(strings.TrimSpace(val[end+1+i:]))
+				}
+				val = val[:end]
+				break
+			}
+			if p.isEOF {
+				return "", fmt.Errorf("missing closing quote of value %q", val)
+			}
+			next, err := p.readUntil('\n')
+			if err != nil {
+				return "", err
+			}
+			val += "\n" + strings.TrimRight(string(next), "\r\n")
+		}
+		if quote == '"' {
+			val = dotEnvUnescaper.Replace(val)
+		}
+		return val, nil
+	}
+
+	if i := strings.Index(val, " #"); i > -1 {
+		p.comment.WriteString(val[i+1:])
+		val = strings.TrimSpace(val[:i])
+	}
+	return val, nil
+}
+
+// closingQuote returns index of the closing quote in s, double quotes escaped by
+// backslash are skipped. It returns -1 if not found.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteDotEnv writes keys of the default section in dotenv format to given io.Writer,
+// values are double-quoted with escapes when needed, "$" is escaped so that it is not
+// expanded by other tools. Other sections are not written, values of sensitive keys
+// are redacted, see Key.SetSensitive.
+func (m *Manager) WriteDotEnv(w io.Writer) (int64, error) {
+	sec, err := m.GetSection("")
+	if err != nil {
+		return 0, err
+	}
+
+	m.mutex.RLock()
+	crlf := m.lineEnding("") == "\r\n"
+	w, bom := m.encode(w)
+	m.mutex.RUnlock()
+	buf := bufio.NewWriter(w)
+	bw := &countingWriter{w: buf, crlf: crlf}
+	bw.writeBOM(bom)
+	for name, key := range sec.All() {
+		writeComment(bw, key.Comment, WriteOptions{})
+		val := key.redacted(key.rawValue())
+		if strings.ContainsAny(val, " \t\r\n#\"'\\$") {
+			val = `"` + dotEnvEscaper.Replace(val) + `"`
+		}
+		bw.WriteString(name + "=" + val + "\n")
+	}
+	if bw.err != nil {
+		return bw.n, bw.err
+	}
+	return bw.n, buf.Flush()
+}
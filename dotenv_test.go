@@ -0,0 +1,30 @@
+package ini
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteDotEnv(t *testing.T) {
+	m := New(Options{DotEnv: true})
+	for name, val := range map[string]string{"PLAIN": "value", "PRICE": "$5", "PATH_LIKE": `C:\dir "x"`} {
+		m.NewSection("").NewKey(name, val)
+	}
+	var buf bytes.Buffer
+	if _, err := m.WriteDotEnv(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`PRICE="\$5"`)) {
+		t.Errorf("$ not escaped:\n%s", buf.String())
+	}
+
+	back, err := LoadSources(Options{DotEnv: true}, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range m.Section("").Keys() {
+		if got := back.Section("").Key(key.Name()).String(); got != key.String() {
+			t.Errorf("%s = %q, want %q", key.Name(), got, key.String())
+		}
+	}
+}
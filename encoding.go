@@ -0,0 +1,164 @@
+package ini
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding converts data between a charset and UTF-8. Encodings of
+// golang.org/x/text can be adapted by calling NewDecoder().Reader(r) and
+// NewEncoder().Writer(w) respectively.
+type Encoding interface {
+	// Decode returns a reader converting data of r to UTF-8.
+	Decode(r io.Reader) io.Reader
+	// Encode returns a writer converting UTF-8 data to the charset before writing to w.
+	Encode(w io.Writer) io.Writer
+}
+
+var (
+	// UTF16LE is the UTF-16 little-endian encoding.
+	UTF16LE Encoding = utf16Encoding{binary.LittleEndian}
+	// UTF16BE is the UTF-16 big-endian encoding.
+	UTF16BE Encoding = utf16Encoding{binary.BigEndian}
+	// Latin1 is the ISO 8859-1 encoding.
+	Latin1 Encoding = latin1Encoding{}
+)
+
+// decodeChunkSize is the number of bytes read at a time when decoding.
+const decodeChunkSize = 4096
+
+type utf16Encoding struct {
+	order interface {
+		binary.ByteOrder
+		binary.AppendByteOrder
+	}
+}
+
+func (e utf16Encoding) Decode(r io.Reader) io.Reader {
+	return &decoder{r: r, decode: func(data, out []byte) ([]byte, []byte) {
+		for len(data) >= 2 {
+			r := rune(e.order.Uint16(data))
+			switch {
+			case r >= 0xd800 && r < 0xdc00:
+				// High surrogate, only a following low surrogate completes it.
+				if len(data) < 4 {
+					return data, out
+				}
+				if r2 := rune(e.order.Uint16(data[2:])); r2 >= 0xdc00 && r2 < 0xe000 {
+					r = utf16.DecodeRune(r, r2)
+					data = data[4:]
+				} else {
+					r = utf8.RuneError
+					data = data[2:]
+				}
+			case utf16.IsSurrogate(r):
+				// Unpaired low surrogate.
+				r = utf8.RuneError
+				data = data[2:]
+			default:
+				data = data[2:]
+			}
+			out = utf8.AppendRune(out, r)
+		}
+		return data, out
+	}}
+}
+
+func (e utf16Encoding) Encode(w io.Writer) io.Writer {
+	return &encoder{w: w, encode: func(r rune, out []byte) ([]byte, error) {
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			out = e.order.AppendUint16(out, uint16(r1))
+			return e.order.AppendUint16(out, uint16(r2)), nil
+		}
+		return e.order.AppendUint16(out, uint16(r)), nil
+	}}
+}
+
+type latin1Encoding struct{}
+
+func (latin1Encoding) Decode(r io.Reader) io.Reader {
+	return &decoder{r: r, decode: func(data, out []byte) ([]byte, []byte) {
+		for _, b := range data {
+			out = utf8.AppendRune(out, rune(b))
+		}
+		return nil, out
+	}}
+}
+
+func (latin1Encoding) Encode(w io.Writer) io.Writer {
+	return &encoder{w: w, encode: func(r rune, out []byte) ([]byte, error) {
+		if r > 0xff {
+			return out, fmt.Errorf("ini: character %q cannot be encoded in Latin-1", r)
+		}
+		return append(out, byte(r)), nil
+	}}
+}
+
+// decoder converts data read from r to UTF-8 chunk by chunk.
+type decoder struct {
+	r io.Reader
+	// decode converts data and appends to out, it returns incomplete trailing bytes.
+	decode func(data, out []byte) (rest, decoded []byte)
+	rest   []byte
+	out    []byte
+	err    error
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			if len(d.rest) == 0 {
+				return 0, d.err
+			}
+			// Incomplete trailing bytes cannot be decoded.
+			d.out = utf8.AppendRune(d.out[:0], utf8.RuneError)
+			d.rest = nil
+			break
+		}
+
+		var chunk [decodeChunkSize]byte
+		n := copy(chunk[:], d.rest)
+		m, err := d.r.Read(chunk[n:])
+		d.err = err
+		var rest []byte
+		rest, d.out = d.decode(chunk[:n+m], d.out[:0])
+		d.rest = append(d.rest[:0], rest...)
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// encoder converts UTF-8 data to a charset before writing to w.
+type encoder struct {
+	w io.Writer
+	// encode appends encoded rune to out.
+	encode func(r rune, out []byte) ([]byte, error)
+	// rest is the incomplete trailing UTF-8 sequence of the last write.
+	rest []byte
+}
+
+func (e *encoder) Write(p []byte) (int, error) {
+	data := append(e.rest, p...)
+	out := make([]byte, 0, len(data)*2)
+	for len(data) > 0 && utf8.FullRune(data) {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+
+		var err error
+		if out, err = e.encode(r, out); err != nil {
+			return 0, err
+		}
+	}
+	e.rest = append(e.rest[:0:0], data...)
+
+	if _, err := e.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
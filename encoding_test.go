@@ -0,0 +1,29 @@
+package ini
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestUTF16Decode(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"ascii", []byte{'a', 0, '=', 0, '1', 0}, "a=1"},
+		{"pair", []byte{0x3d, 0xd8, 0x00, 0xde}, "\U0001F600"},
+		{"unpaired high", []byte{0x3d, 0xd8, 'a', 0, 'b', 0}, "�ab"},
+		{"unpaired low", []byte{0x00, 0xde, 'a', 0}, "�a"},
+		{"trailing high", []byte{'a', 0, 0x3d, 0xd8}, "a�"},
+	} {
+		got, err := io.ReadAll(UTF16LE.Decode(strings.NewReader(string(tt.data))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNoEncryptor is returned when encrypting a value without Options.Encryptor.
+var ErrNoEncryptor = errors.New("ini: no encryptor")
+
+// ErrDecrypt is returned when Options.Decryptor fails to decrypt a value,
+// getters without an error return empty string in place of such a value.
+var ErrDecrypt = errors.New("ini: cannot decrypt value")
+
+// Decryptor decrypts values in form of ENC[ciphertext], see Options.Decryptor.
+type Decryptor interface {
+	// Decrypt returns the plaintext of given ciphertext, i.e. the text between "ENC[" and "]".
+	Decrypt(ciphertext string) (string, error)
+}
+
+// Encryptor encrypts values written in form of ENC[ciphertext], see Options.Encryptor.
+type Encryptor interface {
+	// Encrypt returns the ciphertext of given plaintext, it must not contain "]".
+	Encrypt(plaintext string) (string, error)
+}
+
+// DecryptorFunc is an adapter to use ordinary functions as Decryptor.
+type DecryptorFunc func(ciphertext string) (string, error)
+
+// Decrypt calls f(ciphertext).
+func (f DecryptorFunc) Decrypt(ciphertext string) (string, error) { return f(ciphertext) }
+
+// EncryptorFunc is an adapter to use ordinary functions as Encryptor.
+type EncryptorFunc func(plaintext string) (string, error)
+
+// Encrypt calls f(plaintext).
+func (f EncryptorFunc) Encrypt(plaintext string) (string, error) { return f(plaintext) }
+
+// ciphertext returns the text between "ENC[" and "]" if val is an encrypted value.
+func ciphertext(val string) (string, bool) {
+	if !strings.HasPrefix(val, "ENC[") || !strings.HasSuffix(val, "]") {
+		return "", false
+	}
+	return val[4 : len(val)-1], true
+}
+
+// IsEncrypted returns true if raw value of the key is in form of ENC[ciphertext].
+func (k *Key) IsEncrypted() bool {
+	_, ok := ciphertext(k.rawValue())
+	return ok
+}
+
+// SetEncrypted sets value of the key to given plaintext encrypted by Options.Encryptor.
+func (k *Key) SetEncrypted(plaintext string) error {
+	val, err := encryptValue(k.s.m.options.Encryptor, plaintext)
+	if err != nil {
+		return fmt.Errorf("ini: key %q: %w", k.name, err)
+	}
+	k.SetValue(val)
+	return nil
+}
+
+// encryptValue returns given plaintext encrypted in form of ENC[ciphertext].
+func encryptValue(enc Encryptor, plaintext string) (string, error) {
+	if enc == nil {
+		return "", ErrNoEncryptor
+	}
+	ct, err := enc.Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(ct, "]") {
+		return "", errors.New("ini: ciphertext contains \"]\"")
+	}
+	return "ENC[" + ct + "]", nil
+}
+
+// decrypt returns the plaintext if val is an encrypted value and Options.Decryptor is set,
+// ok reports whether val was decrypted.
+func (k *Key) decrypt(val string) (plain string, ok bool, err error) {
+	dec := k.s.m.options.Decryptor
+	if dec == nil {
+		return val, false, nil
+	}
+	plain, ok, err = k.s.m.plaintexts.decrypt(val, dec)
+	if err != nil {
+		return "", true, fmt.Errorf("ini: key %q: %w: %w", k.name, ErrDecrypt, err)
+	}
+	return plain, ok, nil
+}
+
+// plaintextCache caches decrypted values by their ciphertexts until the next Reload.
+type plaintextCache struct {
+	mu     sync.Mutex
+	values map[string]string
+	hits   int64
+	misses int64
+}
+
+// decrypt returns the plaintext if val is an encrypted value, or val as-is.
+func (c *plaintextCache) decrypt(val string, dec Decryptor) (string, bool, error) {
+	ct, ok := ciphertext(val)
+	if !ok {
+		return val, false, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if plain, ok := c.values[ct]; ok {
+		c.hits++
+		return plain, true, nil
+	}
+	c.misses++
+	plain, err := dec.Decrypt(ct)
+	if err != nil {
+		return "", true, err
+	}
+	if c.values == nil {
+		c.values = make(map[string]string)
+	}
+	c.values[ct] = plain
+	return plain, true, nil
+}
+
+// counts returns the number of cache hits and misses.
+func (c *plaintextCache) counts() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// reset drops all cached plaintexts.
+func (c *plaintextCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.values)
+}
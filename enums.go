@@ -0,0 +1,233 @@
+package ini
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// QuoteStyle is the style used to quote values when writing.
+type QuoteStyle int
+
+const (
+	// QuoteAuto quotes values only when they cannot be parsed back as-is.
+	QuoteAuto QuoteStyle = iota
+	// QuoteDouble surrounds values with double quotes when possible.
+	QuoteDouble
+	// QuoteBacktick surrounds values with backquotes when possible.
+	QuoteBacktick
+	// QuoteTriple surrounds values with triple double quotes.
+	QuoteTriple
+)
+
+var quoteStyleNames = []string{"auto", "double", "backtick", "triple"}
+
+// DuplicatePolicy decides what happens when a key is defined more than once in a section.
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeepFirst keeps the value of the first definition.
+	DuplicateKeepFirst DuplicatePolicy = iota
+	// DuplicateKeepLast keeps the value of the last definition.
+	DuplicateKeepLast
+	// DuplicateError fails parsing on duplicate definitions.
+	DuplicateError
+	// DuplicateShadow keeps the value of the first definition and accumulates
+	// values of others as shadow values, see Key.ValueWithShadows.
+	DuplicateShadow
+)
+
+var duplicatePolicyNames = []string{"keep-first", "keep-last", "error", "shadow"}
+
+// MergePolicy decides what happens when merged data contains an existing key.
+type MergePolicy int
+
+const (
+	// MergeOverwrite replaces existing values.
+	MergeOverwrite MergePolicy = iota
+	// MergeKeepExisting keeps existing values.
+	MergeKeepExisting
+	// MergeError fails merging on existing keys.
+	MergeError
+	// MergeAppendShadow keeps existing values and adds merged values as shadows.
+	MergeAppendShadow
+)
+
+var mergePolicyNames = []string{"overwrite", "keep-existing", "error", "append-shadow"}
+
+// Profile is a well-known INI dialect.
+type Profile int
+
+const (
+	ProfileDefault Profile = iota
+	ProfilePython
+	ProfileMySQL
+	ProfileGit
+	ProfileSystemd
+	ProfileDotEnv
+	ProfileAWS
+)
+
+var profileNames = []string{"default", "python", "mysql", "git", "systemd", "dotenv", "aws"}
+
+// Fidelity is the level of detail preserved when writing.
+type Fidelity int
+
+const (
+	// FidelityFull writes comments along with sections and keys.
+	FidelityFull Fidelity = iota
+	// FidelityValues writes sections and keys with all their values, shadow and
+	// nested values included, but no comments.
+	FidelityValues
+)
+
+var fidelityNames = []string{"full", "values"}
+
+// ControlCharPolicy decides how control characters in values, e.g. NUL and
+// vertical tab, are handled. Tab and line breaks are not control characters here.
+type ControlCharPolicy int
+
+const (
+	// ControlCharPass keeps control characters as-is.
+	ControlCharPass ControlCharPolicy = iota
+	// ControlCharReject fails on values containing control characters.
+	ControlCharReject
+	// ControlCharEscape replaces control characters by \xHH escapes.
+	ControlCharEscape
+)
+
+var controlCharPolicyNames = []string{"pass", "reject", "escape"}
+
+// ASCIIPolicy decides how non-ASCII section and key names are handled when parsing.
+type ASCIIPolicy int
+
+const (
+	// ASCIIPass keeps non-ASCII names as-is.
+	ASCIIPass ASCIIPolicy = iota
+	// ASCIIReject fails on non-ASCII names.
+	ASCIIReject
+	// ASCIITransliterate replaces non-ASCII names by Transliterate, see Manager.Renames.
+	ASCIITransliterate
+)
+
+var asciiPolicyNames = []string{"pass", "reject", "transliterate"}
+
+// OrphanKeyPolicy decides what happens to keys appearing before any section header.
+type OrphanKeyPolicy int
+
+const (
+	// OrphanKeysDefault puts the keys into the default section.
+	OrphanKeysDefault OrphanKeyPolicy = iota
+	// OrphanKeysReject fails on the keys.
+	OrphanKeysReject
+	// OrphanKeysSection puts the keys into the section named by Options.OrphanSection.
+	OrphanKeysSection
+)
+
+var orphanKeyPolicyNames = []string{"default", "reject", "section"}
+
+func (q QuoteStyle) String() string      { return enumString(quoteStyleNames, int(q)) }
+func (d DuplicatePolicy) String() string { return enumString(duplicatePolicyNames, int(d)) }
+func (p MergePolicy) String() string     { return enumString(mergePolicyNames, int(p)) }
+func (p Profile) String() string         { return enumString(profileNames, int(p)) }
+func (f Fidelity) String() string        { return enumString(fidelityNames, int(f)) }
+func (c ControlCharPolicy) String() string {
+	return enumString(controlCharPolicyNames, int(c))
+}
+func (a ASCIIPolicy) String() string { return enumString(asciiPolicyNames, int(a)) }
+func (o OrphanKeyPolicy) String() string {
+	return enumString(orphanKeyPolicyNames, int(o))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (q QuoteStyle) MarshalText() ([]byte, error) { return enumMarshal(quoteStyleNames, int(q)) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (d DuplicatePolicy) MarshalText() ([]byte, error) {
+	return enumMarshal(duplicatePolicyNames, int(d))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p MergePolicy) MarshalText() ([]byte, error) { return enumMarshal(mergePolicyNames, int(p)) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Profile) MarshalText() ([]byte, error) { return enumMarshal(profileNames, int(p)) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (f Fidelity) MarshalText() ([]byte, error) { return enumMarshal(fidelityNames, int(f)) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (c ControlCharPolicy) MarshalText() ([]byte, error) {
+	return enumMarshal(controlCharPolicyNames, int(c))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a ASCIIPolicy) MarshalText() ([]byte, error) { return enumMarshal(asciiPolicyNames, int(a)) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (o OrphanKeyPolicy) MarshalText() ([]byte, error) {
+	return enumMarshal(orphanKeyPolicyNames, int(o))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (q *QuoteStyle) UnmarshalText(text []byte) error {
+	return enumUnmarshal(quoteStyleNames, "QuoteStyle", text, (*int)(q))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *DuplicatePolicy) UnmarshalText(text []byte) error {
+	return enumUnmarshal(duplicatePolicyNames, "DuplicatePolicy", text, (*int)(d))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *MergePolicy) UnmarshalText(text []byte) error {
+	return enumUnmarshal(mergePolicyNames, "MergePolicy", text, (*int)(p))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Profile) UnmarshalText(text []byte) error {
+	return enumUnmarshal(profileNames, "Profile", text, (*int)(p))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *Fidelity) UnmarshalText(text []byte) error {
+	return enumUnmarshal(fidelityNames, "Fidelity", text, (*int)(f))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *ControlCharPolicy) UnmarshalText(text []byte) error {
+	return enumUnmarshal(controlCharPolicyNames, "ControlCharPolicy", text, (*int)(c))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *ASCIIPolicy) UnmarshalText(text []byte) error {
+	return enumUnmarshal(asciiPolicyNames, "ASCIIPolicy", text, (*int)(a))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (o *OrphanKeyPolicy) UnmarshalText(text []byte) error {
+	return enumUnmarshal(orphanKeyPolicyNames, "OrphanKeyPolicy", text, (*int)(o))
+}
+
+func enumString(names []string, v int) string {
+	if v >= 0 && v < len(names) {
+		return names[v]
+	}
+	return strconv.Itoa(v)
+}
+
+func enumMarshal(names []string, v int) ([]byte, error) {
+	if v < 0 || v >= len(names) {
+		return nil, fmt.Errorf("ini: invalid enum value %d", v)
+	}
+	return []byte(names[v]), nil
+}
+
+func enumUnmarshal(names []string, typ string, text []byte, v *int) error {
+	i := slices.Index(names, string(text))
+	if i < 0 {
+		return fmt.Errorf("ini: invalid %s %q", typ, text)
+	}
+	*v = i
+	return nil
+}
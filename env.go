@@ -0,0 +1,43 @@
+package ini
+
+import (
+	"os"
+	"strings"
+)
+
+// LoadEnv loads environment variables with given prefix on top of existing values,
+// e.g. APP_SERVER_PORT=8080 becomes key "port" of section "server" with prefix "APP".
+// Names are split by Options.EnvSeparator, the last part is the key name and others
+// are joined by ChildSectionDelimiter as the section name, so APP_SERVER_HTTP_PORT
+// becomes key "port" of section "server.http". Names are lower-cased.
+// Loaded values are not data sources, they are discarded by Reload.
+func (m *Manager) LoadEnv(prefix string) error {
+	if m.rejectFrozen() {
+		return ErrFrozen
+	}
+
+	sep := m.options.EnvSeparator
+	if len(prefix) > 0 {
+		prefix += sep
+	}
+	for _, env := range os.Environ() {
+		name, val, ok := strings.Cut(env, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+
+		parts := strings.Split(strings.ToLower(name[len(prefix):]), sep)
+		kname := parts[len(parts)-1]
+		if len(kname) == 0 {
+			continue
+		}
+		sec := m.NewSection(strings.Join(parts[:len(parts)-1], m.options.ChildSectionDelimiter))
+		if key, err := sec.GetKeyLocal(kname); err == nil {
+			key.SetValue(val)
+		} else {
+			sec.NewKey(kname, val)
+		}
+		m.contributed.Store(true)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+var (
+	// ErrSectionNotFound is returned when getting a section which does not exist.
+	ErrSectionNotFound = errors.New("ini: section does not exist")
+	// ErrKeyNotFound is returned when getting a key which does not exist.
+	ErrKeyNotFound = errors.New("ini: key does not exist")
+	// ErrEmptyKeyName is returned when parsing a line with a delimiter but no key name.
+	ErrEmptyKeyName = errors.New("ini: empty key name")
+)
+
+// ParseError is returned when parsing a malformed line of a data source.
+type ParseError struct {
+	// Source is the name of the data source, e.g. file path, it may be empty.
+	Source string
+	// Line is the 1-based line number in the data source.
+	Line int
+	// Column is the 1-based column where the malformed entity starts, it is zero if unknown.
+	Column int
+	// Text is the raw text of the line, excluding line break.
+	Text string
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error returns the cause prefixed by the location.
+func (e *ParseError) Error() string {
+	loc := strconv.Itoa(e.Line)
+	if e.Column > 0 {
+		loc += ":" + strconv.Itoa(e.Column)
+	}
+	if len(e.Source) > 0 {
+		return fmt.Sprintf("%s:%s: %v", e.Source, loc, e.Err)
+	}
+	return fmt.Sprintf("line %s: %v", loc, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseWarnings returns malformed lines skipped while parsing data sources with
+// Options.ContinueOnError in order of parsing, they are cleared by Reload.
+func (m *Manager) ParseWarnings() []*ParseError {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return slices.Clone(m.warnings)
+}
+
+// addWarnings records malformed lines skipped while parsing.
+func (m *Manager) addWarnings(warnings []*ParseError) {
+	if len(warnings) == 0 {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.warnings = append(m.warnings, warnings...)
+}
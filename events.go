@@ -0,0 +1,151 @@
+package ini
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// EventType is the kind of an Event.
+type EventType int
+
+const (
+	// EventSourceAppended is emitted after an appended data source was parsed.
+	EventSourceAppended EventType = iota
+	// EventSourceReloaded is emitted after a data source was parsed again by Reload.
+	EventSourceReloaded
+	// EventSourceFailed is emitted when a data source failed to be parsed.
+	EventSourceFailed
+	// EventSectionAdded is emitted when a new section was created.
+	EventSectionAdded
+	// EventKeyChanged is emitted when value of a key was changed by SetValue.
+	EventKeyChanged
+	// EventKeyAdded is emitted when a new key was created.
+	EventKeyAdded
+	// EventScanSection is emitted by Scan for a section header.
+	EventScanSection
+	// EventScanKey is emitted by Scan for a key, or a nested value of the key.
+	EventScanKey
+	// EventScanComment is emitted by Scan for a comment, Value is the comment text.
+	EventScanComment
+)
+
+var eventTypeNames = []string{
+	"SourceAppended", "SourceReloaded", "SourceFailed", "SectionAdded", "KeyChanged", "KeyAdded",
+	"ScanSection", "ScanKey", "ScanComment",
+}
+
+// String returns name of the event type.
+func (t EventType) String() string {
+	if t >= 0 && int(t) < len(eventTypeNames) {
+		return eventTypeNames[t]
+	}
+	return "EventType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Event describes a change in the lifecycle of a Manager.
+type Event struct {
+	Type EventType
+	// Source is the name of the data source, e.g. file path, for source events.
+	Source string
+	// Section is the name of the section for section and key events.
+	Section string
+	// Key is the name of the key for key events.
+	Key string
+	// Value is the new raw value for key events.
+	Value string
+	// OldValue is the previous raw value for EventKeyChanged.
+	OldValue string
+	// Err is the error for EventSourceFailed.
+	Err error
+	// Line is the line number in the data source for events emitted by Scan.
+	Line int
+
+	section *Section
+	key     *Key
+	// repeatable is true for EventScanKey of keys whose definitions do not
+	// conflict when repeated, e.g. nested values, boolean and array keys.
+	repeatable bool
+	// column and text are the column and raw text of the line where the key of
+	// EventScanKey starts.
+	column int
+	text   []byte
+}
+
+// Events dispatches events to registered listeners synchronously,
+// listeners must not block and are called without holding any lock.
+type Events struct {
+	mu        sync.RWMutex
+	nextID    int
+	listeners []listener
+}
+
+type listener struct {
+	id int
+	fn func(Event)
+}
+
+// Subscribe registers a listener and returns a function to unregister it.
+func (e *Events) Subscribe(fn func(Event)) (unsubscribe func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := e.nextID
+	e.nextID++
+	e.listeners = append(e.listeners, listener{id: id, fn: fn})
+
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.listeners = slices.DeleteFunc(e.listeners, func(l listener) bool {
+			return l.id == id
+		})
+	}
+}
+
+// emit dispatches given event to all listeners.
+func (e *Events) emit(ev Event) {
+	e.mu.RLock()
+	if len(e.listeners) == 0 {
+		e.mu.RUnlock()
+		return
+	}
+	listeners := slices.Clone(e.listeners)
+	e.mu.RUnlock()
+
+	for _, l := range listeners {
+		l.fn(ev)
+	}
+}
+
+// OnSet registers a callback fired after value of a key was changed by SetValue.
+func (m *Manager) OnSet(fn func(k *Key, old, new string)) (unsubscribe func()) {
+	return m.events.Subscribe(func(ev Event) {
+		if ev.Type == EventKeyChanged {
+			fn(ev.key, ev.OldValue, ev.Value)
+		}
+	})
+}
+
+// OnNewKey registers a callback fired after a new key was created.
+func (m *Manager) OnNewKey(fn func(k *Key)) (unsubscribe func()) {
+	return m.events.Subscribe(func(ev Event) {
+		if ev.Type == EventKeyAdded {
+			fn(ev.key)
+		}
+	})
+}
+
+// OnNewSection registers a callback fired after a new section was created.
+func (m *Manager) OnNewSection(fn func(s *Section)) (unsubscribe func()) {
+	return m.events.Subscribe(func(ev Event) {
+		if ev.Type == EventSectionAdded {
+			fn(ev.section)
+		}
+	})
+}
+
+// Events returns the event bus of the manager.
+func (m *Manager) Events() *Events {
+	return m.events
+}
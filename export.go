@@ -0,0 +1,88 @@
+package ini
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ToFlags renders keys as command-line arguments in form of
+// --<prefix><section>-<key>=<value>, keys of the default section are
+// rendered as --<prefix><key>=<value> and boolean keys as --<prefix><key>.
+func (m *Manager) ToFlags(prefix string) []string {
+	flagName := strings.NewReplacer(m.options.ChildSectionDelimiter, "-", " ", "-", "_", "-")
+
+	var flags []string
+	for sec := range m.All() {
+		for name, key := range sec.All() {
+			if len(sec.name) > 0 {
+				name = sec.name + "-" + name
+			}
+			flag := "--" + prefix + flagName.Replace(name)
+			if key.isBooleanType {
+				flags = append(flags, flag)
+				continue
+			}
+			flags = append(flags, flag+"="+key.expand())
+		}
+	}
+	return flags
+}
+
+// ToEnv renders keys as environment variables in form of PREFIX_SECTION_KEY=value,
+// e.g. key "host" of section "db" becomes APP_DB_HOST with prefix "APP". Names are
+// upper-cased and characters other than letters and digits are replaced by '_',
+// keys of the default section are rendered as PREFIX_KEY=value. An error is returned
+// when keys collide, e.g. key "b.c" of section "a" and key "c" of section "a.b".
+func (m *Manager) ToEnv(prefix string) ([]string, error) {
+	var env []string
+	keys := make(map[string]string)
+	for sec := range m.All() {
+		for name, key := range sec.All() {
+			path := name
+			if len(sec.name) > 0 {
+				path = sec.Name() + m.options.ChildSectionDelimiter + name
+				name = sec.name + "_" + name
+			}
+			if len(prefix) > 0 {
+				name = prefix + "_" + name
+			}
+			name = envName(name)
+			if other, ok := keys[name]; ok {
+				return nil, fmt.Errorf("ini: keys %q and %q are both rendered as %s", other, path, name)
+			}
+			keys[name] = path
+			env = append(env, name+"="+key.expand())
+		}
+	}
+	return env, nil
+}
+
+// SetEnv sets keys as environment variables of the current process, see ToEnv.
+// Nothing is set when keys collide.
+func (m *Manager) SetEnv(prefix string) error {
+	env, err := m.ToEnv(prefix)
+	if err != nil {
+		return err
+	}
+	for _, v := range env {
+		name, val, _ := strings.Cut(v, "=")
+		if err := os.Setenv(name, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envName returns given name upper-cased with characters other than letters and digits replaced by '_'.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,78 @@
+package ini
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Prefixes of values referring to files.
+var fileReferencePrefixes = []string{"@file:", "file://"}
+
+// fileCache caches contents of referenced files until the next Reload.
+type fileCache struct {
+	mu     sync.Mutex
+	files  map[string]string
+	hits   int64
+	misses int64
+}
+
+// resolve returns contents of the file if val is a file reference, or val as-is.
+func (c *fileCache) resolve(val string, maxSize int64) (string, error) {
+	var path string
+	for _, prefix := range fileReferencePrefixes {
+		if strings.HasPrefix(val, prefix) {
+			path = strings.TrimSpace(val[len(prefix):])
+			break
+		}
+	}
+	if len(path) == 0 {
+		return val, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if data, ok := c.files[path]; ok {
+		c.hits++
+		return data, nil
+	}
+	c.misses++
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// Read one more byte to detect oversized files.
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("ini: referenced file %q exceeds %d bytes", path, maxSize)
+	}
+
+	if c.files == nil {
+		c.files = make(map[string]string)
+	}
+	c.files[path] = string(data)
+	return c.files[path], nil
+}
+
+// counts returns the number of cache hits and misses.
+func (c *fileCache) counts() (hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// reset drops all cached contents.
+func (c *fileCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.files)
+}
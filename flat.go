@@ -0,0 +1,56 @@
+package ini
+
+import (
+	"slices"
+	"strings"
+)
+
+// FromFlatMap returns a new Manager loaded from flat keys, e.g. data pulled from
+// Redis or etcd. Keys are split at the last sep into section and key name, so
+// "server.http.port" becomes key "port" of section "server.http". Keys without
+// sep belong to the default section. The sep is used as Options.ChildSectionDelimiter,
+// it defaults to ".".
+func FromFlatMap(data map[string]string, sep string) *Manager {
+	m := New(Options{ChildSectionDelimiter: sep})
+	sep = m.options.ChildSectionDelimiter
+
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		section, key := "", name
+		if i := strings.LastIndex(name, sep); i > -1 {
+			section, key = name[:i], name[i+len(sep):]
+		}
+		m.NewSection(section).NewKey(key, data[name])
+		m.contributed.Store(true)
+	}
+	m.markClean()
+	return m
+}
+
+// ToFlatMap returns all keys as flat entries, e.g. key "port" of section "server.http"
+// becomes "server.http.port" with sep ".". The ChildSectionDelimiter in section names
+// is replaced by sep, keys of the default section are not prefixed.
+// It is the inverse of FromFlatMap, sep defaults to ".". Values of sensitive keys
+// are redacted, see Key.SetSensitive.
+func (m *Manager) ToFlatMap(sep string) map[string]string {
+	if len(sep) == 0 {
+		sep = "."
+	}
+
+	out := make(map[string]string)
+	for sec := range m.All() {
+		prefix := ""
+		if len(sec.name) > 0 {
+			prefix = strings.ReplaceAll(sec.Name(), m.options.ChildSectionDelimiter, sep) + sep
+		}
+		for name, key := range sec.All() {
+			out[prefix+name] = key.redacted(key.expand())
+		}
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package ini
+
+import "errors"
+
+// ErrFrozen is returned when modifying a frozen Manager.
+var ErrFrozen = errors.New("ini: the manager is frozen")
+
+// Freeze makes the manager read-only, all further modifications are rejected
+// with ErrFrozen, or panic when Options.PanicOnFrozen is set. Section and key
+// lookups of a frozen manager take no locks, so readers never contend.
+// Freeze must not be called concurrently with modifications.
+func (m *Manager) Freeze() {
+	m.materialize()
+	// Wait for readers holding the lock, they unlock with respect of the frozen state.
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.frozen.Store(true)
+}
+
+// IsFrozen returns true if the manager was frozen.
+func (m *Manager) IsFrozen() bool {
+	return m.frozen.Load()
+}
+
+// readLock read-locks the manager unless it is frozen and returns whether it did,
+// it is used as defer m.readUnlock(m.readLock()).
+func (m *Manager) readLock() bool {
+	if m.frozen.Load() {
+		return false
+	}
+	m.mutex.RLock()
+	return true
+}
+
+// readUnlock unlocks the manager if it was read-locked by readLock.
+func (m *Manager) readUnlock(locked bool) {
+	if locked {
+		m.mutex.RUnlock()
+	}
+}
+
+// rejectFrozen returns true if the manager is frozen and the modification
+// should be skipped, it panics when Options.PanicOnFrozen is set.
+func (m *Manager) rejectFrozen() bool {
+	if !m.frozen.Load() {
+		return false
+	}
+	if m.options.PanicOnFrozen {
+		panic(ErrFrozen)
+	}
+	return true
+}
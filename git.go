@@ -0,0 +1,54 @@
+package ini
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gitSectionName returns the section name of a git-style section header, e.g.
+// `remote "origin"` becomes "remote.origin" with the default ChildSectionDelimiter.
+// Following git, section names are case-insensitive and forced to lowercase
+// while subsection names are case-sensitive and kept as-is.
+// Docs: https://git-scm.com/docs/git-config#_syntax
+func (m *Manager) gitSectionName(header string) (string, error) {
+	i := strings.IndexAny(header, " \t")
+	if i < 0 {
+		// Deprecated [section.subsection] syntax is case-insensitive as a whole.
+		return strings.ToLower(header), nil
+	}
+
+	name := strings.ToLower(header[:i])
+	sub := strings.TrimLeft(header[i:], " \t")
+	if len(sub) < 2 || sub[0] != '"' || sub[len(sub)-1] != '"' {
+		return "", fmt.Errorf("invalid subsection header: %s", header)
+	}
+	sub = sub[1 : len(sub)-1]
+
+	var b strings.Builder
+	b.Grow(len(sub))
+	for i := 0; i < len(sub); i++ {
+		c := sub[i]
+		if c == '\\' && i+1 < len(sub) {
+			i++
+			c = sub[i]
+		} else if c == '"' {
+			return "", fmt.Errorf("invalid subsection header: %s", header)
+		}
+		b.WriteByte(c)
+	}
+	return name + m.options.ChildSectionDelimiter + b.String(), nil
+}
+
+// sectionHeader returns the text between brackets of the header of given section,
+// child sections are written as git-style subsections when Options.GitSubsections is set.
+func (m *Manager) sectionHeader(name string) string {
+	if !m.options.GitSubsections {
+		return quoteSectionName(name)
+	}
+	sec, sub, ok := strings.Cut(name, m.options.ChildSectionDelimiter)
+	if !ok {
+		return name
+	}
+	sub = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(sub)
+	return sec + ` "` + sub + `"`
+}
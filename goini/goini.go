@@ -0,0 +1,322 @@
+// Package ini is a compatibility layer mirroring the API of gopkg.in/ini.v1 on top of
+// go-slim.dev/ini, codebases using the former can migrate by replacing the import path
+// with "go-slim.dev/ini/goini". The package name stays ini.
+//
+// Only the commonly used subset is provided: File, Section and Key, loading functions
+// and LoadOptions. The underlying Manager and Section are embedded, so the rest of the
+// API of go-slim.dev/ini is available as well.
+package ini
+
+import (
+	"errors"
+	"strings"
+
+	base "go-slim.dev/ini"
+)
+
+// DefaultSection is the name of the default section, it refers to keys before any header.
+const DefaultSection = "DEFAULT"
+
+// Key is a key of a section.
+type Key = base.Key
+
+// DebugFunc is the type of function called to collect debug information.
+type DebugFunc func(message string)
+
+// LoadOptions contains options used when loading data sources.
+type LoadOptions struct {
+	// Loose indicates whether the parser should ignore nonexistent files or return error.
+	Loose bool
+	// Insensitive indicates whether the parser forces all section and key names to lowercase.
+	Insensitive bool
+	// InsensitiveSections indicates whether the parser forces all section names to lowercase.
+	InsensitiveSections bool
+	// InsensitiveKeys indicates whether the parser forces all key names to lowercase.
+	InsensitiveKeys bool
+	// IgnoreContinuation indicates whether to ignore continuation lines while parsing.
+	IgnoreContinuation bool
+	// IgnoreInlineComment indicates whether to ignore comments at the end of value and treat it as value.
+	IgnoreInlineComment bool
+	// SkipUnrecognizableLines indicates whether to skip unrecognizable lines that do not conform to key/value pairs.
+	SkipUnrecognizableLines bool
+	// AllowBooleanKeys indicates whether to allow boolean type keys or treat as value is missing.
+	AllowBooleanKeys bool
+	// AllowShadows indicates whether to keep track of keys with same name under same section.
+	AllowShadows bool
+	// AllowNestedValues indicates whether to allow AWS-like nested values.
+	AllowNestedValues bool
+	// AllowPythonMultilineValues indicates whether to allow Python-like multi-line values.
+	AllowPythonMultilineValues bool
+	// SpaceBeforeInlineComment indicates whether to allow comment symbols only after a space.
+	SpaceBeforeInlineComment bool
+	// UnescapeValueDoubleQuotes indicates whether to unescape double quotes inside value to regular format.
+	UnescapeValueDoubleQuotes bool
+	// UnescapeValueCommentSymbols indicates to unescape comment symbols (\# and \;) inside value to regular format.
+	UnescapeValueCommentSymbols bool
+	// KeyValueDelimiters is the sequence of delimiters used to separate key and value, by default it is "=:".
+	KeyValueDelimiters string
+	// ChildSectionDelimiter is the delimiter used to separate child sections, by default it is ".".
+	ChildSectionDelimiter string
+	// PreserveSurroundedQuote indicates whether to preserve surrounded quote (single and double quotes).
+	PreserveSurroundedQuote bool
+	// DebugFunc is called to collect debug information.
+	DebugFunc DebugFunc
+	// ReaderBufferSize is the buffer size of the reader in bytes.
+	ReaderBufferSize int
+	// AllowNonUniqueSections indicates whether to allow sections with the same name multiple times.
+	AllowNonUniqueSections bool
+	// AllowDuplicateShadowValues indicates whether values for shadowed keys should be deduplicated.
+	AllowDuplicateShadowValues bool
+}
+
+// options returns the equivalent options of go-slim.dev/ini.
+func (o LoadOptions) options() base.Options {
+	opts := base.Options{
+		Loose:                       o.Loose,
+		Insensitive:                 o.Insensitive,
+		InsensitiveSections:         o.InsensitiveSections,
+		InsensitiveKeys:             o.InsensitiveKeys,
+		IgnoreContinuation:          o.IgnoreContinuation,
+		IgnoreInlineComment:         o.IgnoreInlineComment,
+		ContinueOnError:             o.SkipUnrecognizableLines,
+		AllowBooleanKeys:            o.AllowBooleanKeys,
+		AllowNestedValues:           o.AllowNestedValues,
+		AllowPythonMultilineValues:  o.AllowPythonMultilineValues,
+		SpaceBeforeInlineComment:    o.SpaceBeforeInlineComment,
+		UnescapeValueDoubleQuotes:   o.UnescapeValueDoubleQuotes,
+		UnescapeValueCommentSymbols: o.UnescapeValueCommentSymbols,
+		KeyValueDelimiters:          o.KeyValueDelimiters,
+		ChildSectionDelimiter:       o.ChildSectionDelimiter,
+		PreserveSurroundedQuote:     o.PreserveSurroundedQuote,
+		DebugFunc:                   o.DebugFunc,
+		ReaderBufferSize:            o.ReaderBufferSize,
+		AllowNonUniqueSections:      o.AllowNonUniqueSections,
+		AllowDuplicateShadowValues:  o.AllowDuplicateShadowValues,
+	}
+	// The last value of duplicate keys is kept unless shadows are allowed.
+	opts.DuplicateKeys = base.DuplicateKeepLast
+	if o.AllowShadows {
+		opts.DuplicateKeys = base.DuplicateShadow
+	}
+	return opts
+}
+
+// File represents a combination of one or more INI files in memory.
+type File struct {
+	*base.Manager
+	delim string // delimiter of child sections
+}
+
+// Empty returns an empty file object, options are optional.
+func Empty(opts ...LoadOptions) *File {
+	f, _ := LoadSources(firstOptions(opts), []byte(""))
+	return f
+}
+
+// Load loads and parses from INI data sources, arguments can be a mix of file names
+// with string type, []byte and io.Reader.
+func Load(source any, others ...any) (*File, error) {
+	return LoadSources(LoadOptions{}, source, others...)
+}
+
+// LooseLoad has exactly same functionality as Load function except it ignores
+// nonexistent files instead of returning error.
+func LooseLoad(source any, others ...any) (*File, error) {
+	return LoadSources(LoadOptions{Loose: true}, source, others...)
+}
+
+// InsensitiveLoad has exactly same functionality as Load function except it forces
+// all section and key names to be lowercased.
+func InsensitiveLoad(source any, others ...any) (*File, error) {
+	return LoadSources(LoadOptions{Insensitive: true}, source, others...)
+}
+
+// ShadowLoad has exactly same functionality as Load function except it allows
+// having shadow keys.
+func ShadowLoad(source any, others ...any) (*File, error) {
+	return LoadSources(LoadOptions{AllowShadows: true}, source, others...)
+}
+
+// LoadSources allows caller to apply customized options for loading from data sources.
+func LoadSources(opts LoadOptions, source any, others ...any) (*File, error) {
+	m, err := base.LoadSources(opts.options(), append([]any{source}, others...)...)
+	if err != nil {
+		return nil, err
+	}
+	delim := opts.ChildSectionDelimiter
+	if len(delim) == 0 {
+		delim = "."
+	}
+	return &File{Manager: m, delim: delim}, nil
+}
+
+// MapTo maps data sources to given struct.
+func MapTo(v, source any, others ...any) error {
+	f, err := Load(source, others...)
+	if err != nil {
+		return err
+	}
+	return f.MapTo(v)
+}
+
+// firstOptions returns the first of given options, or zero-value options.
+func firstOptions(opts []LoadOptions) LoadOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return LoadOptions{}
+}
+
+// sectionName maps DefaultSection to the default section of the manager.
+func sectionName(name string) string {
+	if name == DefaultSection {
+		return ""
+	}
+	return name
+}
+
+// NewSection creates a new section.
+func (f *File) NewSection(name string) (*Section, error) {
+	if len(name) == 0 {
+		return nil, errors.New("empty section name")
+	}
+	return f.section(f.Manager.NewSection(sectionName(name))), nil
+}
+
+// NewSections creates a list of sections.
+func (f *File) NewSections(names ...string) error {
+	for _, name := range names {
+		if _, err := f.NewSection(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSection returns section by given name.
+func (f *File) GetSection(name string) (*Section, error) {
+	sec, err := f.Manager.GetSection(sectionName(name))
+	if err != nil {
+		return nil, err
+	}
+	return f.section(sec), nil
+}
+
+// HasSection checks if the file contains a section with given name.
+func (f *File) HasSection(name string) bool {
+	return f.Manager.HasSection(sectionName(name))
+}
+
+// Section assumes named section exists and creates it when not.
+func (f *File) Section(name string) *Section {
+	if sec, err := f.GetSection(name); err == nil {
+		return sec
+	}
+	return f.section(f.Manager.NewSection(sectionName(name)))
+}
+
+// Sections returns a list of sections in order of definition.
+func (f *File) Sections() []*Section {
+	var sections []*Section
+	for sec := range f.All() {
+		sections = append(sections, f.section(sec))
+	}
+	return sections
+}
+
+// SectionStrings returns list of section names in order of definition.
+func (f *File) SectionStrings() []string {
+	var names []string
+	for sec := range f.All() {
+		names = append(names, f.section(sec).Name())
+	}
+	return names
+}
+
+// ChildSections returns a list of child sections of given section name.
+func (f *File) ChildSections(name string) []*Section {
+	return f.Section(name).ChildSections()
+}
+
+// section wraps given section.
+func (f *File) section(sec *base.Section) *Section {
+	return &Section{Section: sec, f: f}
+}
+
+// DeleteSection deletes a section.
+func (f *File) DeleteSection(name string) {
+	f.Manager.DeleteSection(sectionName(name))
+}
+
+// Section represents a section of a File.
+type Section struct {
+	*base.Section
+	f *File
+}
+
+// Name returns name of the section, the default section is named DefaultSection.
+func (s *Section) Name() string {
+	if name := s.Section.Name(); len(name) > 0 {
+		return name
+	}
+	return DefaultSection
+}
+
+// Key assumes named key exists in the section and creates it with empty value when not.
+func (s *Section) Key(name string) *Key {
+	if key, err := s.GetKey(name); err == nil {
+		return key
+	}
+	if len(name) == 0 {
+		return s.Section.Key(name)
+	}
+	return s.Section.NewKey(name, "")
+}
+
+// NewKey creates a new key to the section, the value of an existing key is replaced.
+func (s *Section) NewKey(name, val string) (*Key, error) {
+	if len(name) == 0 {
+		return nil, base.ErrEmptyKeyName
+	}
+	if key, err := s.GetKeyLocal(name); err == nil {
+		key.SetValue(val)
+		return key, nil
+	}
+	return s.Section.NewKey(name, val), nil
+}
+
+// NewBooleanKey creates a new boolean type key to the section.
+func (s *Section) NewBooleanKey(name string) (*Key, error) {
+	if len(name) == 0 {
+		return nil, base.ErrEmptyKeyName
+	}
+	return s.Section.NewBooleanKey(name), nil
+}
+
+// KeyStrings returns list of key names of the section.
+func (s *Section) KeyStrings() []string {
+	keys := s.Keys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.Name()
+	}
+	return names
+}
+
+// DeleteKey deletes a key from the section.
+func (s *Section) DeleteKey(name string) {
+	s.Section.DeleteKey(name)
+}
+
+// ChildSections returns a list of child sections of the section, children of the
+// default section are named with DefaultSection as the parent.
+func (s *Section) ChildSections() []*Section {
+	prefix := s.Name() + s.f.delim
+	var children []*Section
+	for sec := range s.f.All() {
+		if strings.HasPrefix(sec.Name(), prefix) {
+			children = append(children, s.f.section(sec))
+		}
+	}
+	return children
+}
@@ -0,0 +1,93 @@
+package ini
+
+import (
+	"slices"
+	"testing"
+)
+
+const testData = `name = app
+
+[server]
+port = 80
+port = 8080
+
+[server.http]
+host = localhost
+
+[server.tls]
+cert = a.pem
+
+[DEFAULT.child]
+key = value
+`
+
+func TestCompatibility(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		fn   func(f *File) any
+		want any
+	}{
+		{"load", func(f *File) any { return f.Section("").Key("name").String() }, "app"},
+		{"default section name", func(f *File) any { return f.Section("").Name() }, DefaultSection},
+		{"default section by name", func(f *File) any { return f.Section(DefaultSection).Key("name").String() }, "app"},
+		{"has default section", func(f *File) any { return f.HasSection(DefaultSection) }, true},
+		{"section strings", func(f *File) any { return f.SectionStrings() },
+			[]string{DefaultSection, "server", "server.http", "server.tls", "DEFAULT.child"}},
+		{"duplicate keys keep last", func(f *File) any { return f.Section("server").Key("port").String() }, "8080"},
+		{"new key", func(f *File) any {
+			key, err := f.Section("server").NewKey("host", "example.com")
+			if err != nil {
+				return err
+			}
+			return key.String()
+		}, "example.com"},
+		{"new key replaces", func(f *File) any {
+			if _, err := f.Section("server").NewKey("port", "443"); err != nil {
+				return err
+			}
+			return f.Section("server").Key("port").String()
+		}, "443"},
+		{"new key empty name", func(f *File) any {
+			_, err := f.Section("server").NewKey("", "value")
+			return err != nil
+		}, true},
+		{"child sections", func(f *File) any { return names(f.ChildSections("server")) },
+			[]string{"server.http", "server.tls"}},
+		{"child sections of default", func(f *File) any { return names(f.Section("").ChildSections()) },
+			[]string{"DEFAULT.child"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Load([]byte(testData))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := tt.fn(f)
+			if gs, ok := got.([]string); ok {
+				if !slices.Equal(gs, tt.want.([]string)) {
+					t.Errorf("got %q, want %q", gs, tt.want)
+				}
+			} else if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShadowLoad(t *testing.T) {
+	f, err := ShadowLoad([]byte(testData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Section("server").Key("port").ValueWithShadows(); !slices.Equal(got, []string{"80", "8080"}) {
+		t.Errorf("shadows of port = %q, want [80 8080]", got)
+	}
+}
+
+// names returns names of given sections.
+func names(sections []*Section) []string {
+	var names []string
+	for _, sec := range sections {
+		names = append(names, sec.Name())
+	}
+	return names
+}
@@ -0,0 +1,85 @@
+package ini
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// Maximum allowed depth of nested include directives.
+const maxIncludeDepth = 10
+
+// directive handles a MySQL-style directive line, e.g. "!include /etc/mysql/extra.cnf",
+// unknown directives are ignored.
+// Docs: https://dev.mysql.com/doc/refman/8.0/en/option-files.html#option-file-inclusion
+func (p *parser) directive(line string) error {
+	name, arg := line, ""
+	if i := strings.IndexFunc(line, unicode.IsSpace); i > -1 {
+		name, arg = line[:i], strings.TrimSpace(line[i:])
+	}
+
+	switch name {
+	case "!include":
+		return p.include(p.includePath(arg))
+	case "!includedir":
+		dir := p.includePath(arg)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) && p.m.options.Loose {
+				return nil
+			}
+			return err
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".cnf" && (runtime.GOOS != "windows" || ext != ".ini")) {
+				continue
+			}
+			if err = p.include(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p.debug("directive: ignore unknown directive", slog.String("directive", line))
+	return nil
+}
+
+// include parses given file into the manager, the path is already resolved by includePath.
+func (p *parser) include(name string) error {
+	if p.depth >= maxIncludeDepth {
+		return fmt.Errorf("include depth exceeds %d: %s", maxIncludeDepth, name)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) && p.m.options.Loose {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	child := newParser(f, p.m, name)
+	defer child.release()
+	child.depth = p.depth + 1
+	child.scan = p.scan
+	child.layer = p.layer
+	err = child.parse()
+	p.sections += child.sections
+	p.keys += child.keys
+	return err
+}
+
+// includePath resolves relative path against directory of the current data source.
+func (p *parser) includePath(name string) string {
+	if filepath.IsAbs(name) || len(p.source) == 0 {
+		return name
+	}
+	return filepath.Join(filepath.Dir(p.source), name)
+}
@@ -0,0 +1,104 @@
+package ini
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the guessed type of a key value.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindBool
+	KindInt
+	KindFloat
+	KindDuration
+	KindTime
+	KindList
+)
+
+var kindNames = [...]string{
+	KindString:   "string",
+	KindBool:     "bool",
+	KindInt:      "int",
+	KindFloat:    "float",
+	KindDuration: "duration",
+	KindTime:     "time",
+	KindList:     "list",
+}
+
+// String returns name of the kind.
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// InferTypes guesses type of every key from its value, grouped by section name.
+func (m *Manager) InferTypes() map[string]map[string]Kind {
+	m.mutex.RLock()
+	names := make([]string, len(m.sectionList))
+	copy(names, m.sectionList)
+	m.mutex.RUnlock()
+
+	kinds := make(map[string]map[string]Kind, len(names))
+	for _, name := range names {
+		sec, err := m.GetSection(name)
+		if err != nil {
+			continue
+		}
+		keys := sec.Keys()
+		kinds[name] = make(map[string]Kind, len(keys))
+		for _, key := range keys {
+			kinds[name][key.Name()] = key.InferType()
+		}
+	}
+	return kinds
+}
+
+// InferType guesses type of key from its value.
+func (k *Key) InferType() Kind {
+	return inferKind(k.String())
+}
+
+// isDecimalFloat reports whether val is a decimal floating-point number, i.e. made of
+// digits, signs, a decimal point and an exponent. Unlike strconv.ParseFloat, words
+// like "nan" and "inf" and hexadecimal numbers are not accepted.
+func isDecimalFloat(val string) bool {
+	if strings.Trim(val, "0123456789+-.eE") != "" {
+		return false
+	}
+	_, err := strconv.ParseFloat(val, 64)
+	return err == nil
+}
+
+// inferKind guesses type of given value, numbers are checked before
+// booleans so that "1" and "0" are treated as integers.
+func inferKind(val string) Kind {
+	val = strings.TrimSpace(val)
+	if len(val) == 0 {
+		return KindString
+	}
+	if _, err := strconv.ParseInt(val, 0, 64); err == nil {
+		return KindInt
+	}
+	if isDecimalFloat(val) {
+		return KindFloat
+	}
+	if _, err := parseBool(val); err == nil {
+		return KindBool
+	}
+	if _, err := time.ParseDuration(val); err == nil {
+		return KindDuration
+	}
+	if _, err := time.Parse(time.RFC3339, val); err == nil {
+		return KindTime
+	}
+	if strings.Contains(val, ",") {
+		return KindList
+	}
+	return KindString
+}
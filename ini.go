@@ -1,6 +1,10 @@
 package ini
 
-import "sync"
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
 
 // Options contains all customized options used for load data source(s).
 type Options struct {
@@ -10,10 +14,21 @@ type Options struct {
 	Insensitive bool
 	// InsensitiveSections indicates whether the parser forces all section to lowercase.
 	InsensitiveSections bool
+	// GitSubsections indicates whether to parse git-style section headers like [remote "origin"]
+	// as child sections, e.g. remote.origin, and to write child sections that way.
+	// Section names are forced to lowercase while subsection names are kept as-is.
+	GitSubsections bool
 	// InsensitiveKeys indicates whether the parser forces all key names to lowercase.
 	InsensitiveKeys bool
+	// PreserveCase indicates whether section and key names keep the casing they were
+	// first written with when Insensitive, InsensitiveSections or InsensitiveKeys is set,
+	// lookups are still case-insensitive. See Section.Name and Key.Name.
+	PreserveCase bool
 	// IgnoreContinuation indicates whether to ignore continuation lines while parsing.
 	IgnoreContinuation bool
+	// ContinuationJoin is inserted between continuation lines when joining them,
+	// e.g. " " for systemd units. By default, lines are joined directly.
+	ContinuationJoin string
 	// IgnoreInlineComment indicates whether to ignore comments at the end of value and treat it as part of value.
 	IgnoreInlineComment bool
 	// AllowBooleanKeys indicates whether to allow boolean type keys or treat as value is missing.
@@ -24,6 +39,33 @@ type Options struct {
 	// Relevant quote:  Values can also span multiple lines, as long as they are indented deeper
 	// than the first line of the value.
 	AllowPythonMultilineValues bool
+	// DotEnv indicates whether to parse data in dotenv format: there are no sections, keys
+	// may be prefixed by "export", double-quoted values support escapes and may span
+	// multiple lines, and single-quoted values are taken literally. See Manager.WriteDotEnv.
+	DotEnv bool
+	// AllowIncludeDirectives indicates whether to handle MySQL-style !include and !includedir
+	// directives by parsing the referenced files, other directives are ignored.
+	// Docs: https://dev.mysql.com/doc/refman/8.0/en/option-files.html#option-file-inclusion
+	AllowIncludeDirectives bool
+	// OrphanKeys decides what happens to keys appearing before any section header,
+	// by default they belong to the default section.
+	OrphanKeys OrphanKeyPolicy
+	// OrphanSection is the name of the section for keys appearing before any section
+	// header with OrphanKeysSection. By default, it is "orphan".
+	OrphanSection string
+	// AllowArrayKeys indicates whether to accumulate values of PHP-style array keys like
+	// name[] = value as one key "name", see Key.ValueWithShadows and Key.Strings.
+	AllowArrayKeys bool
+	// AllowAppendOperator indicates whether name += value appends to the value of an
+	// existing key instead of defining it again, see Key.Append.
+	AllowAppendOperator bool
+	// AppendSeparator is inserted between values joined by the append operator and
+	// Key.Append. By default, it is a space.
+	AppendSeparator string
+	// AllowNestedValues indicates whether to allow indented lines following a key with
+	// empty value to be parsed as its sub-values, e.g. gitconfig or pip-style configs.
+	// Docs: https://pip.pypa.io/en/stable/topics/configuration/
+	AllowNestedValues bool
 	// SpaceBeforeInlineComment indicates whether to allow comment symbols (\# and \;) inside value.
 	// Docs: https://docs.python.org/2/library/configparser.html
 	// Quote: Comments may appear on their own in an otherwise empty line, or may be entered in lines holding values or section names.
@@ -36,25 +78,154 @@ type Options struct {
 	// when value is NOT surrounded by any quotes.
 	// Note: UNSTABLE, behavior might change to only unescape inside double quotes but may noy necessary at all.
 	UnescapeValueCommentSymbols bool
+	// DisableParentInheritance indicates whether to stop looking up keys in parent sections,
+	// e.g. [app.worker] no longer falls back to keys of [app].
+	DisableParentInheritance bool
+	// ASCIINames decides how non-ASCII section and key names are handled when parsing,
+	// e.g. for configs consumed by parsers which only handle ASCII identifiers.
+	ASCIINames ASCIIPolicy
 	// KeyValueDelimiters is the sequence of delimiters that are used to separate key and value. By default, it is "=:".
 	KeyValueDelimiters string
+	// KeyValueDelimiterRequiresSpace indicates whether a delimiter only separates key and
+	// value when followed by whitespace or end of line, e.g. "url: http://host" with ":".
+	KeyValueDelimiterRequiresSpace bool
+	// PreferFirstDelimiter indicates whether delimiters are tried in order of KeyValueDelimiters,
+	// e.g. with "=:" the key of "http://host = allow" is "http://host". By default, the
+	// earliest delimiter in the line separates key and value.
+	PreferFirstDelimiter bool
 	// ChildSectionDelimiter is the delimiter that is used to separate child sections. By default, it is ".".
 	ChildSectionDelimiter string
+	// CreateParentSections indicates whether to create missing parent sections along with
+	// a child section, e.g. [a] and [a.b] for [a.b.c], and to delete such parent sections
+	// left without keys and children by DeleteSection.
+	CreateParentSections bool
 	// PreserveSurroundedQuote indicates whether to preserve surrounded quote (single and double quotes).
 	PreserveSurroundedQuote bool
 	// DebugFunc is called to collect debug information (currently only useful to debug parsing Python-style multiline values).
+	//
+	// Deprecated: Use Logger, which receives the same messages as structured records.
 	DebugFunc func(message string)
+	// Logger receives structured records of parsing, reloading and transforming values,
+	// with attributes such as source, line, section, key and error. Parsing details are
+	// logged at debug level, skipped lines and failed transforms at warn level and failed
+	// data sources at error level. Nothing is logged when nil.
+	Logger *slog.Logger
+	// Encoding is the charset of data sources, data is decoded to UTF-8 before parsing
+	// and encoded when writing. UTF-16 data starting with a BOM is decoded when nil.
+	Encoding Encoding
 	// ReaderBufferSize is the buffer size of the reader in bytes.
 	ReaderBufferSize int
 	// AllowNonUniqueSections indicates whether to allow sections with the same name multiple times.
 	AllowNonUniqueSections bool
+	// DuplicateKeys decides what happens when a key is defined more than once in a section,
+	// by default the value of the first definition is kept.
+	DuplicateKeys DuplicatePolicy
 	// AllowDuplicateShadowValues indicates whether values for shadowed keys should be deduplicated.
 	AllowDuplicateShadowValues bool
+	// KeepPreviousValues indicates whether to retain the previous value of each key
+	// after Reload or SetValue, see Key.PreviousValue.
+	KeepPreviousValues bool
+	// SpillThreshold is the size in bytes beyond which parsed values are stored in
+	// temporary files and read lazily, see Key.Reader. Zero disables spilling.
+	SpillThreshold int
+	// SpillDir is the directory for temporary files of spilled values,
+	// the default directory for temporary files is used when empty.
+	SpillDir string
+	// DefaultTimeLocation is the location used to parse time values whose layout
+	// lacks time zone information, UTC is used when nil.
+	DefaultTimeLocation *time.Location
+	// PanicOnFrozen indicates whether to panic instead of ignoring modifications
+	// of a frozen Manager, see Manager.Freeze.
+	PanicOnFrozen bool
+	// Schema lists known sections and keys, unknown ones are discarded while parsing.
+	Schema Schema
+	// RejectUnknown indicates whether to fail parsing on sections and keys unknown
+	// to Schema instead of discarding them.
+	RejectUnknown bool
+	// ControlChars decides how control characters in parsed values are handled,
+	// by default they are kept as-is.
+	ControlChars ControlCharPolicy
+	// ContinueOnError indicates whether to continue parsing after syntax errors, malformed
+	// lines are skipped and recorded into Manager.ParseWarnings instead of being returned.
+	// Scan returns all errors together by errors.Join.
+	ContinueOnError bool
 	// Mutex Should make things safe, but sometimes doesn't matter.
 	Mutex Mutex
 	// ValueMapper represents a mapping function for values
 	ValueMapper func(m *Manager, s *Section, k *Key) string
 	Transformer ValueTransformer
+	// ResolveFileReferences indicates whether to resolve values in form of
+	// "@file:/path/to/file" or "file:///path/to/file" to contents of the file.
+	ResolveFileReferences bool
+	// MaxFileReferenceSize is the maximum size in bytes of a referenced file,
+	// by default it is 1 MiB.
+	MaxFileReferenceSize int64
+	// EnvSeparator is the separator of section and key names in environment variables
+	// loaded by LoadEnv. By default, it is "_".
+	EnvSeparator string
+	// Defines are values available to interpolation by %(name)s and ${name} without
+	// existing in any section, e.g. version numbers injected by build pipelines.
+	// Keys of sections take precedence for %(name)s, and defines take precedence
+	// over environment variables for ${name}.
+	Defines map[string]string
+	// EnvAllow restricts environment variables resolved by ${name} to given names,
+	// a name ending with "*" is a prefix, e.g. "APP_*". All variables are allowed
+	// when it is empty. Variables not allowed are treated as unset, defines are
+	// not restricted.
+	EnvAllow []string
+	// EnvDeny excludes environment variables from being resolved by ${name} in the
+	// same form as EnvAllow, it takes precedence over EnvAllow.
+	EnvDeny []string
+	// SensitiveKeys are name patterns of keys whose values are redacted in exports,
+	// e.g. "*password*", matched case-insensitively, see path.Match for the pattern
+	// syntax and Key.SetSensitive.
+	SensitiveKeys []string
+	// RevealSensitive indicates whether values of sensitive keys are exported as-is.
+	RevealSensitive bool
+	// Decryptor decrypts values in form of ENC[ciphertext] when they are read, decrypted
+	// values are cached until the next Reload and never interpolated. Raw values are kept
+	// encrypted, e.g. in exports. Encrypted values are read as-is when nil, and values
+	// failing to decrypt are read as empty strings, see ErrDecrypt.
+	Decryptor Decryptor
+	// Encryptor encrypts values set by Key.SetEncrypted, and plaintext values of sensitive
+	// keys when saving to files, see Key.SetSensitive.
+	Encryptor Encryptor
+	// MaxExpandedLength is the maximum length in bytes of a value after interpolation,
+	// by default it is 1 MiB.
+	MaxExpandedLength int
+	// MaxSubstitutions is the maximum number of distinct references resolved while
+	// interpolating a single value, by default it is 99.
+	MaxSubstitutions int
+	// ConcurrentSources is the maximum number of data sources parsed in parallel when
+	// appending or reloading many sources, parsed data is still merged in order.
+	// Sources are parsed one by one when it is less than 2.
+	ConcurrentSources int
+	// TracerProvider is used to emit spans around appending, parsing, reloading and saving data sources.
+	TracerProvider TracerProvider
+	// OnParse is called after each data source was parsed with statistics of the parse,
+	// it may be called concurrently when ConcurrentSources is set. See Manager.Stats.
+	OnParse func(SourceStats)
+	// TrackReads indicates whether to record which keys were read by value getters,
+	// e.g. String, Int and MapTo, see Manager.UnreadKeys.
+	TrackReads bool
+	// Condition enables conditional section headers, e.g. [service if GOOS=linux] or
+	// [db @production]. It is given the condition, i.e. "GOOS=linux" or "@production",
+	// and reports whether the section is loaded under the name before the condition,
+	// keys of excluded sections are discarded. Headers are taken literally when nil.
+	// Data loaded with a condition cannot be saved, see ErrConditional and ConditionContext.
+	Condition func(cond string) bool
+	// DisableDefaultWriteback indicates whether Must* getters leave values of keys as-is
+	// when returning the given default, by default the default replaces the empty or
+	// malformed value of the key and is written out by WriteTo.
+	DisableDefaultWriteback bool
+	// LazySections indicates whether to only index section headers of data sources on load,
+	// keys of a section are parsed when the section is first accessed, e.g. by GetSection
+	// or All. Errors in keys of a section are recorded as parse warnings then, so it requires
+	// ContinueOnError and is ignored when DuplicateKeys, OrphanKeys or ControlChars reject
+	// data. It is also ignored along with DotEnv, AllowIncludeDirectives, AllowNestedValues,
+	// AllowPythonMultilineValues, Encoding or ConcurrentSources, which require data sources
+	// to be fully parsed.
+	LazySections bool
 }
 
 type Mutex interface {
@@ -71,6 +242,24 @@ func New(opts Options) *Manager {
 	if len(opts.ChildSectionDelimiter) == 0 {
 		opts.ChildSectionDelimiter = "."
 	}
+	if len(opts.OrphanSection) == 0 {
+		opts.OrphanSection = "orphan"
+	}
+	if len(opts.AppendSeparator) == 0 {
+		opts.AppendSeparator = " "
+	}
+	if len(opts.EnvSeparator) == 0 {
+		opts.EnvSeparator = "_"
+	}
+	if opts.MaxExpandedLength <= 0 {
+		opts.MaxExpandedLength = defaultMaxExpandedLength
+	}
+	if opts.MaxFileReferenceSize <= 0 {
+		opts.MaxFileReferenceSize = defaultMaxExpandedLength
+	}
+	if opts.MaxSubstitutions <= 0 {
+		opts.MaxSubstitutions = depthValues
+	}
 	if opts.Mutex == nil {
 		opts.Mutex = &sync.RWMutex{}
 	}
@@ -78,5 +267,7 @@ func New(opts Options) *Manager {
 		options:  opts,
 		sections: make(map[string]*Section),
 		mutex:    opts.Mutex,
+		events:   &Events{},
+		stats:    &statsCounter{},
 	}
 }
@@ -0,0 +1,102 @@
+// Package initest provides helpers for testing applications that consume INI data.
+package initest
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// CorpusSpec describes the shape of a generated INI document.
+type CorpusSpec struct {
+	// Sections is the number of named sections, default section is always generated.
+	Sections int
+	// Keys is the maximum number of keys in each section.
+	Keys int
+	// Quotes indicates whether to generate quoted and backquoted values.
+	Quotes bool
+	// Multiline indicates whether to generate triple-quoted multi-line values.
+	Multiline bool
+	// Unicode indicates whether to use non-ASCII characters in names and values.
+	Unicode bool
+	// Comments indicates whether to generate comment lines.
+	Comments bool
+}
+
+var (
+	asciiWords   = []string{"alpha", "beta", "gamma", "delta", "host", "port", "path", "user", "timeout", "mode"}
+	unicodeWords = []string{"名前", "ключ", "clé", "ταχύτητα", "数据", "größe"}
+)
+
+// Generate returns a valid INI document built from given seed and spec,
+// the same seed and spec always produce the same document.
+func Generate(seed int64, spec CorpusSpec) []byte {
+	g := &generator{rnd: rand.New(rand.NewSource(seed)), spec: spec}
+
+	var buf bytes.Buffer
+	g.keys(&buf)
+	for i := 0; i < spec.Sections; i++ {
+		buf.WriteByte('\n')
+		g.comment(&buf)
+		fmt.Fprintf(&buf, "[%s_%d]\n", g.word(), i)
+		g.keys(&buf)
+	}
+	return buf.Bytes()
+}
+
+type generator struct {
+	rnd  *rand.Rand
+	spec CorpusSpec
+}
+
+func (g *generator) word() string {
+	if g.spec.Unicode && g.rnd.Intn(3) == 0 {
+		return unicodeWords[g.rnd.Intn(len(unicodeWords))]
+	}
+	return asciiWords[g.rnd.Intn(len(asciiWords))]
+}
+
+func (g *generator) comment(buf *bytes.Buffer) {
+	if !g.spec.Comments || g.rnd.Intn(2) == 0 {
+		return
+	}
+	prefix := "#"
+	if g.rnd.Intn(2) == 0 {
+		prefix = ";"
+	}
+	fmt.Fprintf(buf, "%s %s %s\n", prefix, g.word(), g.word())
+}
+
+func (g *generator) keys(buf *bytes.Buffer) {
+	if g.spec.Keys <= 0 {
+		return
+	}
+	n := 1 + g.rnd.Intn(g.spec.Keys)
+	for i := 0; i < n; i++ {
+		g.comment(buf)
+		// Suffix keeps key names unique within the section.
+		fmt.Fprintf(buf, "%s_%d = %s\n", g.word(), i, g.value())
+	}
+}
+
+func (g *generator) value() string {
+	words := make([]string, 1+g.rnd.Intn(3))
+	for i := range words {
+		words[i] = g.word()
+	}
+	val := strings.Join(words, " ")
+
+	switch kind := g.rnd.Intn(5); {
+	case g.spec.Multiline && kind == 0:
+		return `"""` + val + "\n" + g.word() + `"""`
+	case g.spec.Quotes && kind == 1:
+		return `"` + val + `"`
+	case g.spec.Quotes && kind == 2:
+		// Comment symbols are kept as-is inside backquotes.
+		return "`" + val + " #" + g.word() + "`"
+	case kind == 3:
+		return fmt.Sprint(g.rnd.Intn(100000))
+	}
+	return val
+}
@@ -0,0 +1,62 @@
+package initest_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"go-slim.dev/ini"
+	"go-slim.dev/ini/initest"
+)
+
+// snapshot returns every section, key, value and comment of given manager in order.
+func snapshot(m *ini.Manager) string {
+	var b bytes.Buffer
+	for sec := range m.All() {
+		fmt.Fprintf(&b, "[%s] %q\n", sec.Name(), sec.Comment)
+		for name, key := range sec.All() {
+			fmt.Fprintf(&b, "%s = %q %q\n", name, key.Value(), key.Comment)
+		}
+	}
+	return b.String()
+}
+
+// TestRoundTrip checks that written data parses back to the same values,
+// and that writing it again produces the same bytes.
+func TestRoundTrip(t *testing.T) {
+	specs := []initest.CorpusSpec{
+		{Sections: 3, Keys: 5},
+		{Sections: 5, Keys: 8, Quotes: true, Comments: true},
+		{Sections: 5, Keys: 8, Multiline: true, Unicode: true},
+		{Sections: 10, Keys: 10, Quotes: true, Multiline: true, Unicode: true, Comments: true},
+	}
+	for _, spec := range specs {
+		for seed := range int64(50) {
+			data := initest.Generate(seed, spec)
+			m, err := ini.Load(data)
+			if err != nil {
+				t.Fatalf("seed %d, spec %+v: load generated data: %v", seed, spec, err)
+			}
+
+			var first bytes.Buffer
+			if _, err = m.WriteTo(&first); err != nil {
+				t.Fatalf("seed %d, spec %+v: write: %v", seed, spec, err)
+			}
+			again, err := ini.Load(first.Bytes())
+			if err != nil {
+				t.Fatalf("seed %d, spec %+v: load written data: %v\n%s", seed, spec, err, first.String())
+			}
+			if want, got := snapshot(m), snapshot(again); want != got {
+				t.Fatalf("seed %d, spec %+v: values changed by round-trip:\nwant:\n%s\ngot:\n%s", seed, spec, want, got)
+			}
+
+			var second bytes.Buffer
+			if _, err = again.WriteTo(&second); err != nil {
+				t.Fatalf("seed %d, spec %+v: write again: %v", seed, spec, err)
+			}
+			if first.String() != second.String() {
+				t.Fatalf("seed %d, spec %+v: output is not stable:\n%s\nthen:\n%s", seed, spec, first.String(), second.String())
+			}
+		}
+	}
+}
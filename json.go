@@ -0,0 +1,138 @@
+package ini
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MarshalJSON implements json.Marshaler. Keys of the default section are placed at
+// the top level and other sections are placed under their names as objects, in order
+// of definition. Sections take precedence over keys of the default section with the
+// same name, which are skipped. Raw values are used, i.e. values are not interpolated,
+// and values of sensitive keys are redacted, see Key.SetSensitive.
+func (m *Manager) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for sec := range m.All() {
+		if len(sec.name) == 0 {
+			for name, key := range sec.All() {
+				if m.HasSection(name) {
+					continue
+				}
+				writeJSONField(&buf, &first, name, nil, key.redacted(key.rawValue()))
+			}
+			continue
+		}
+		data, err := sec.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		writeJSONField(&buf, &first, sec.Name(), data, "")
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalJSON implements json.Marshaler. Keys are written as an object of
+// raw values in order of definition, values of sensitive keys are redacted.
+func (s *Section) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	for name, key := range s.All() {
+		writeJSONField(&buf, &first, name, nil, key.redacted(key.rawValue()))
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeJSONField writes a member of JSON object, raw is written as-is if not nil,
+// otherwise val is written as a string.
+func writeJSONField(buf *bytes.Buffer, first *bool, name string, raw []byte, val string) {
+	if !*first {
+		buf.WriteByte(',')
+	}
+	*first = false
+	data, _ := json.Marshal(name)
+	buf.Write(data)
+	buf.WriteByte(':')
+	if raw == nil {
+		raw, _ = json.Marshal(val)
+	}
+	buf.Write(raw)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it accepts the structure written by
+// MarshalJSON and adds or updates sections and keys in order. The Manager must be
+// created by New.
+func (m *Manager) UnmarshalJSON(data []byte) error {
+	if m.mutex == nil {
+		return errors.New("ini: UnmarshalJSON requires a Manager created by New")
+	}
+	if m.rejectFrozen() {
+		return ErrFrozen
+	}
+
+	return decodeJSONObject(data, func(name string, raw json.RawMessage) error {
+		if len(raw) > 0 && raw[0] == '{' {
+			return m.NewSection(name).UnmarshalJSON(raw)
+		}
+		return m.NewSection("").decodeJSONValue(name, raw)
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it accepts an object of string values
+// and adds or updates keys in order. The Section must be created by Manager.NewSection.
+func (s *Section) UnmarshalJSON(data []byte) error {
+	if s.m == nil {
+		return errors.New("ini: UnmarshalJSON requires a Section created by Manager.NewSection")
+	}
+	if s.m.rejectFrozen() {
+		return ErrFrozen
+	}
+
+	return decodeJSONObject(data, s.decodeJSONValue)
+}
+
+// decodeJSONValue updates value of the key with given name from a JSON string,
+// the key is created if not exists.
+func (s *Section) decodeJSONValue(name string, raw json.RawMessage) error {
+	var val string
+	if err := json.Unmarshal(raw, &val); err != nil {
+		return fmt.Errorf("ini: key %q of section %q: %w", name, s.name, err)
+	}
+	if key, err := s.GetKeyLocal(name); err == nil {
+		key.SetValue(val)
+		return nil
+	}
+	s.NewKey(name, val)
+	return nil
+}
+
+// decodeJSONObject decodes a JSON object and calls fn for every member in order.
+func decodeJSONObject(data []byte, fn func(name string, raw json.RawMessage) error) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("ini: unexpected JSON value %v, object expected", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return err
+		}
+		if err = fn(tok.(string), raw); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
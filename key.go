@@ -1,8 +1,9 @@
 package ini
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
+	"log/slog"
 	"slices"
 	"strconv"
 	"strings"
@@ -13,10 +14,25 @@ import (
 type Key struct {
 	s               *Section
 	name            string
+	display         string // name as written, see Options.PreserveCase
 	value           string
 	Comment         string
 	isAutoIncrement bool
 	isBooleanType   bool
+	isArray         bool
+	isAppend        bool   // defined by the append operator without prior definition
+	inlineComment   string // trailing line of Comment parsed at the end of the key line
+	nestedValues    []string
+	shadows         []string
+	source          string
+	line            int
+	spill           string
+	meta            map[string]any
+	previous        string
+	hasPrevious     bool
+	sensitivity     int8   // 1 if marked as sensitive, -1 if unmarked, see SetSensitive
+	layer           *Layer // layer of the data source which supplied the value, see AsLayer
+	computed        *computed
 }
 
 // newKey simply return a key object with given values.
@@ -28,18 +44,98 @@ func newKey(s *Section, name, val string) *Key {
 	}
 }
 
-// Name returns name of key.
+// Name returns name of key, as written when Options.PreserveCase is set.
 func (k *Key) Name() string {
+	if len(k.display) > 0 {
+		return k.display
+	}
 	return k.name
 }
 
 // Value returns raw value of key for performance purpose.
 func (k *Key) Value() string {
-	return k.value
+	k.markRead()
+	return k.rawValue()
+}
+
+// Position returns the data source and line number where the key was defined,
+// line is zero if the key was not parsed from any data source.
+func (k *Key) Position() (source string, line int) {
+	return k.source, k.line
+}
+
+// setPosition records where the key was first defined.
+func (k *Key) setPosition(source string, line int) {
+	if k.line == 0 {
+		k.source, k.line = source, line
+	}
+}
+
+// ValueWithShadows returns raw value of key followed by its shadow values, encrypted
+// values are decrypted by Options.Decryptor and empty when decryption fails.
+func (k *Key) ValueWithShadows() []string {
+	k.markRead()
+	vals := append([]string{k.rawValue()}, k.shadows...)
+	for i, val := range vals {
+		plain, ok, err := k.decrypt(val)
+		if err != nil {
+			k.s.m.log(slog.LevelWarn, "ini: failed to decrypt value", slog.String("section", k.s.name),
+				slog.String("key", k.name), errorAttr(err))
+		}
+		if ok {
+			vals[i] = plain
+		}
+	}
+	return vals
+}
+
+// addShadow adds a shadow value to the key, duplicated values are skipped
+// unless Options.AllowDuplicateShadowValues is set.
+func (k *Key) addShadow(val string) {
+	if !k.s.m.options.AllowDuplicateShadowValues &&
+		(val == k.rawValue() || slices.Contains(k.shadows, val)) {
+		return
+	}
+	k.shadows = append(k.shadows, val)
 }
 
-// String returns string representation of value.
+// NestedValues returns nested values stored in the key.
+// It is possible returned value is nil if no nested values stored in the key.
+func (k *Key) NestedValues() []string {
+	return k.nestedValues
+}
+
+// addNestedValue adds a nested value to the key.
+func (k *Key) addNestedValue(val string) {
+	k.nestedValues = append(k.nestedValues, val)
+}
+
+// String returns string representation of value, raw value is returned when
+// interpolation exceeds the limits, and empty string when decryption fails.
 func (k *Key) String() string {
+	k.markRead()
+	return k.expand()
+}
+
+// expand is like String but it is not tracked as a read, see Options.TrackReads.
+func (k *Key) expand() string {
+	val, err := transformValue(k)
+	if err != nil {
+		k.s.m.log(slog.LevelWarn, "ini: failed to transform value", slog.String("section", k.s.name),
+			slog.String("key", k.name), errorAttr(err))
+		if errors.Is(err, ErrDecrypt) {
+			return ""
+		}
+		return k.rawValue()
+	}
+	return val
+}
+
+// Expanded returns string representation of value, ErrExpansionLimit is returned
+// when interpolation exceeds Options.MaxExpandedLength or Options.MaxSubstitutions,
+// and ErrDecrypt when Options.Decryptor fails.
+func (k *Key) Expanded() (string, error) {
+	k.markRead()
 	return transformValue(k)
 }
 
@@ -101,9 +197,45 @@ func (k *Key) Duration() (time.Duration, error) {
 	return time.ParseDuration(k.String())
 }
 
-// TimeFormat parses with given format and returns time.Time type value.
+// TimeFormat parses with given format and returns time.Time type value,
+// Options.DefaultTimeLocation is used when the value lacks time zone information.
 func (k *Key) TimeFormat(format string) (time.Time, error) {
-	return time.Parse(format, k.String())
+	return k.TimeFormatIn(format, k.timeLocation())
+}
+
+// TimeFormatIn parses with given format and returns time.Time type value,
+// given location is used when the value lacks time zone information.
+func (k *Key) TimeFormatIn(format string, loc *time.Location) (time.Time, error) {
+	return parseTimeIn(format, k.String(), loc)
+}
+
+// localRFC3339 is the RFC3339 layout without time zone, e.g. 2006-01-02T15:04:05.
+const localRFC3339 = "2006-01-02T15:04:05"
+
+// parseTimeIn parses val with given format in given location. Values without time zone
+// are accepted for time.RFC3339 format, they are parsed in the location.
+func parseTimeIn(format, val string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation(format, val, loc)
+	if err != nil && format == time.RFC3339 {
+		if local, lerr := time.ParseInLocation(localRFC3339, val, loc); lerr == nil {
+			return local, nil
+		}
+	}
+	return t, err
+}
+
+// TimeIn parses with RFC3339 format and returns time.Time type value,
+// given location is used when the value lacks time zone, e.g. 2006-01-02T15:04:05.
+func (k *Key) TimeIn(loc *time.Location) (time.Time, error) {
+	return k.TimeFormatIn(time.RFC3339, loc)
+}
+
+// timeLocation returns the default location for parsing time values.
+func (k *Key) timeLocation() *time.Location {
+	if loc := k.s.m.options.DefaultTimeLocation; loc != nil {
+		return loc
+	}
+	return time.UTC
 }
 
 // Time parses with RFC3339 format and returns time.Time type value.
@@ -115,18 +247,35 @@ func (k *Key) Time() (time.Time, error) {
 func (k *Key) MustString(defaultVal string) string {
 	val := k.String()
 	if len(val) == 0 {
-		k.value = defaultVal
+		k.storeDefault(defaultVal)
 		return defaultVal
 	}
 	return val
 }
 
+// storeDefault stores default value used by Must* getters as key value,
+// unless Options.DisableDefaultWriteback is set.
+func (k *Key) storeDefault(v string) {
+	if k.s.m.frozen.Load() || k.s.m.options.DisableDefaultWriteback {
+		return
+	}
+
+	k.s.m.mutex.Lock()
+	defer k.s.m.mutex.Unlock()
+	k.s.m.dropSpilled(k.spill)
+	k.value = v
+	k.spill = ""
+	k.s.dirty = true
+	// No event is emitted for defaults.
+	k.s.m.invalidateComputed()
+}
+
 // MustBool always returns value without error,
 // it returns false if error occurs.
 func (k *Key) MustBool(defaultVal ...bool) bool {
 	val, err := k.Bool()
 	if len(defaultVal) > 0 && err != nil {
-		k.value = strconv.FormatBool(defaultVal[0])
+		k.storeDefault(strconv.FormatBool(defaultVal[0]))
 		return defaultVal[0]
 	}
 	return val
@@ -137,7 +286,7 @@ func (k *Key) MustBool(defaultVal ...bool) bool {
 func (k *Key) MustFloat64(defaultVal ...float64) float64 {
 	val, err := k.Float64()
 	if len(defaultVal) > 0 && err != nil {
-		k.value = strconv.FormatFloat(defaultVal[0], 'f', -1, 64)
+		k.storeDefault(strconv.FormatFloat(defaultVal[0], 'f', -1, 64))
 		return defaultVal[0]
 	}
 	return val
@@ -148,7 +297,7 @@ func (k *Key) MustFloat64(defaultVal ...float64) float64 {
 func (k *Key) MustInt(defaultVal ...int) int {
 	val, err := k.Int()
 	if len(defaultVal) > 0 && err != nil {
-		k.value = strconv.FormatInt(int64(defaultVal[0]), 10)
+		k.storeDefault(strconv.FormatInt(int64(defaultVal[0]), 10))
 		return defaultVal[0]
 	}
 	return val
@@ -159,7 +308,7 @@ func (k *Key) MustInt(defaultVal ...int) int {
 func (k *Key) MustInt64(defaultVal ...int64) int64 {
 	val, err := k.Int64()
 	if len(defaultVal) > 0 && err != nil {
-		k.value = strconv.FormatInt(defaultVal[0], 10)
+		k.storeDefault(strconv.FormatInt(defaultVal[0], 10))
 		return defaultVal[0]
 	}
 	return val
@@ -170,7 +319,7 @@ func (k *Key) MustInt64(defaultVal ...int64) int64 {
 func (k *Key) MustUint(defaultVal ...uint) uint {
 	val, err := k.Uint()
 	if len(defaultVal) > 0 && err != nil {
-		k.value = strconv.FormatUint(uint64(defaultVal[0]), 10)
+		k.storeDefault(strconv.FormatUint(uint64(defaultVal[0]), 10))
 		return defaultVal[0]
 	}
 	return val
@@ -181,7 +330,7 @@ func (k *Key) MustUint(defaultVal ...uint) uint {
 func (k *Key) MustUint64(defaultVal ...uint64) uint64 {
 	val, err := k.Uint64()
 	if len(defaultVal) > 0 && err != nil {
-		k.value = strconv.FormatUint(defaultVal[0], 10)
+		k.storeDefault(strconv.FormatUint(defaultVal[0], 10))
 		return defaultVal[0]
 	}
 	return val
@@ -192,7 +341,7 @@ func (k *Key) MustUint64(defaultVal ...uint64) uint64 {
 func (k *Key) MustDuration(defaultVal ...time.Duration) time.Duration {
 	val, err := k.Duration()
 	if len(defaultVal) > 0 && err != nil {
-		k.value = defaultVal[0].String()
+		k.storeDefault(defaultVal[0].String())
 		return defaultVal[0]
 	}
 	return val
@@ -203,7 +352,7 @@ func (k *Key) MustDuration(defaultVal ...time.Duration) time.Duration {
 func (k *Key) MustTimeFormat(format string, defaultVal ...time.Time) time.Time {
 	val, err := k.TimeFormat(format)
 	if len(defaultVal) > 0 && err != nil {
-		k.value = defaultVal[0].Format(format)
+		k.storeDefault(defaultVal[0].Format(format))
 		return defaultVal[0]
 	}
 	return val
@@ -347,39 +496,76 @@ func (k *Key) RangeTime(defaultVal, min, max time.Time) time.Time {
 	return k.RangeTimeFormat(time.RFC3339, defaultVal, min, max)
 }
 
-// Strings returns list of string divided by given delimiter.
+// Strings returns list of string divided by given delimiter,
+// all values of array keys are returned as-is, see Options.AllowArrayKeys.
 func (k *Key) Strings(delim string) []string {
+	if k.isArray {
+		return k.ValueWithShadows()
+	}
+
 	str := k.String()
 	if len(str) == 0 {
 		return []string{}
 	}
 
-	runes := []rune(str)
+	if len(delim) == 0 {
+		return []string{strings.TrimSpace(str)}
+	}
+
+	// Fast path for values without escaped delimiters, elements are substrings of the value.
+	if !strings.Contains(str, `\`) {
+		vals := strings.Split(str, delim)
+		if len(vals[len(vals)-1]) == 0 {
+			vals = vals[:len(vals)-1]
+		}
+		for i := range vals {
+			vals[i] = strings.TrimSpace(vals[i])
+		}
+		return vals
+	}
+
+	// Positions of the next backslash and delimiter are looked up only when passed.
 	vals := make([]string, 0, 2)
-	var buf bytes.Buffer
-	escape := false
-	idx := 0
-	for {
-		if escape {
-			escape = false
-			if runes[idx] != '\\' && !strings.HasPrefix(string(runes[idx:]), delim) {
-				buf.WriteRune('\\')
+	var buf strings.Builder
+	buf.Grow(len(str))
+	esc, sep := -1, -1
+	for i := 0; i < len(str); {
+		if esc < i {
+			if esc = strings.IndexByte(str[i:], '\\'); esc > -1 {
+				esc += i
+			} else {
+				esc = len(str)
 			}
-			buf.WriteRune(runes[idx])
-		} else {
-			if runes[idx] == '\\' {
-				escape = true
-			} else if strings.HasPrefix(string(runes[idx:]), delim) {
-				idx += len(delim) - 1
-				vals = append(vals, strings.TrimSpace(buf.String()))
-				buf.Reset()
+		}
+		if sep < i {
+			if sep = strings.Index(str[i:], delim); sep > -1 {
+				sep += i
 			} else {
-				buf.WriteRune(runes[idx])
+				sep = len(str)
 			}
 		}
-		idx++
-		if idx == len(runes) {
-			break
+
+		switch {
+		case sep < esc:
+			buf.WriteString(str[i:sep])
+			vals = append(vals, strings.TrimSpace(buf.String()))
+			buf.Reset()
+			i = sep + len(delim)
+		case esc < len(str):
+			buf.WriteString(str[i:esc])
+			// Trailing backslash is dropped, escaped backslash and first byte of
+			// escaped delimiter are written as-is, otherwise the backslash is kept.
+			if i = esc + 1; i == len(str) {
+				break
+			}
+			if str[i] != '\\' && !strings.HasPrefix(str[i:], delim) {
+				buf.WriteByte('\\')
+			}
+			buf.WriteByte(str[i])
+			i++
+		default:
+			buf.WriteString(str[i:])
+			i = len(str)
 		}
 	}
 
@@ -390,6 +576,79 @@ func (k *Key) Strings(delim string) []string {
 	return vals
 }
 
+// StringsWithQuotes returns list of string divided by given delimiter,
+// elements surrounded by double quotes may contain the delimiter and
+// use two double quotes ("") to represent a literal one, e.g.
+//
+//	names = "a, inc", b => ["a, inc", "b"]
+func (k *Key) StringsWithQuotes(delim string) []string {
+	str := k.String()
+	if len(str) == 0 {
+		return []string{}
+	}
+
+	vals := make([]string, 0, 2)
+	var buf strings.Builder
+	quoted := false   // current element started with a quote
+	inQuotes := false // inside the quoted part of current element
+	for i := 0; i < len(str); {
+		c := str[i]
+		switch {
+		case inQuotes:
+			if c == '"' {
+				if i+1 < len(str) && str[i+1] == '"' {
+					buf.WriteByte('"')
+					i += 2
+					continue
+				}
+				inQuotes = false
+			} else {
+				buf.WriteByte(c)
+			}
+			i++
+		case len(delim) > 0 && strings.HasPrefix(str[i:], delim):
+			if quoted {
+				vals = append(vals, buf.String())
+			} else {
+				vals = append(vals, strings.TrimSpace(buf.String()))
+			}
+			buf.Reset()
+			quoted = false
+			i += len(delim)
+		case c == '"' && !quoted && len(strings.TrimSpace(buf.String())) == 0:
+			buf.Reset()
+			quoted = true
+			inQuotes = true
+			i++
+		default:
+			// Whitespace between the closing quote and the delimiter is ignored.
+			if !quoted || (c != ' ' && c != '\t') {
+				buf.WriteByte(c)
+			}
+			i++
+		}
+	}
+
+	if quoted {
+		vals = append(vals, buf.String())
+	} else if buf.Len() > 0 {
+		vals = append(vals, strings.TrimSpace(buf.String()))
+	}
+
+	return vals
+}
+
+// MustStrings always returns value without error,
+// it returns default value if the list is empty.
+func (k *Key) MustStrings(delim string, defaultVal []string) []string {
+	vals := k.Strings(delim)
+	if len(vals) == 0 && len(defaultVal) > 0 {
+		k.storeDefault(strings.Join(defaultVal, delim))
+		return defaultVal
+	}
+	return vals
+}
+
 // Float64s returns list of float64 divided by given delimiter. Any invalid input will be treated as zero value.
 func (k *Key) Float64s(delim string) []float64 {
 	vals, _ := k.parseFloat64s(k.Strings(delim), true, false)
@@ -637,8 +896,9 @@ type Parser func(str string) (any, error)
 // parseTimesFormat transforms strings to times in given format.
 func (k *Key) parseTimesFormat(format string, strs []string, addInvalid, returnOnInvalid bool) ([]time.Time, error) {
 	vals := make([]time.Time, 0, len(strs))
+	loc := k.timeLocation()
 	parser := func(str string) (any, error) {
-		val, err := time.Parse(format, str)
+		val, err := parseTimeIn(format, str, loc)
 		return val, err
 	}
 	rawVals, err := k.doParse(strs, addInvalid, returnOnInvalid, parser)
@@ -667,9 +927,92 @@ func (k *Key) doParse(strs []string, addInvalid, returnOnInvalid bool, parser Pa
 
 // SetValue changes key value.
 func (k *Key) SetValue(v string) {
+	if k.s.m.rejectFrozen() {
+		return
+	}
+
 	k.s.m.mutex.Lock()
+	defer k.s.m.events.emit(Event{
+		Type:     EventKeyChanged,
+		Section:  k.s.name,
+		Key:      k.name,
+		Value:    v,
+		OldValue: k.value,
+		section:  k.s,
+		key:      k,
+	})
 	defer k.s.m.mutex.Unlock()
 
+	if k.s.m.options.KeepPreviousValues {
+		k.previous, k.hasPrevious = k.rawValue(), true
+	}
 	k.value = v
-	k.s.keysHash[k.name] = v
+	k.s.m.dropSpilled(k.spill)
+	k.spill = ""
+	k.s.dirty = true
+}
+
+// Append appends given value to the raw value of key, separated by
+// Options.AppendSeparator unless the raw value is empty.
+func (k *Key) Append(v string) {
+	if old := k.rawValue(); len(old) > 0 {
+		v = old + k.s.m.options.AppendSeparator + v
+	}
+	k.SetValue(v)
+}
+
+// PreviousValue returns the raw value of key before the latest Reload or SetValue,
+// it requires Options.KeepPreviousValues and returns false if no value retained.
+func (k *Key) PreviousValue() (string, bool) {
+	return k.previous, k.hasPrevious
+}
+
+// SetComment replaces comment of key, every line is normalized
+// to start with a comment symbol, "; " is added when missing.
+func (k *Key) SetComment(comment string) {
+	if k.s.m.rejectFrozen() {
+		return
+	}
+
+	k.s.m.mutex.Lock()
+	defer k.s.m.mutex.Unlock()
+
+	k.Comment = normalizeComment(comment)
+	k.s.dirty = true
+}
+
+// AppendComment appends lines to the comment of key with normalization.
+func (k *Key) AppendComment(comment string) {
+	if k.s.m.rejectFrozen() {
+		return
+	}
+
+	k.s.m.mutex.Lock()
+	defer k.s.m.mutex.Unlock()
+
+	comment = normalizeComment(comment)
+	if len(k.Comment) > 0 && len(comment) > 0 {
+		k.Comment += "\n" + comment
+	} else {
+		k.Comment += comment
+	}
+	k.s.dirty = true
+}
+
+// normalizeComment ensures every non-empty line of comment starts with '#' or ';'.
+func normalizeComment(comment string) string {
+	comment = strings.TrimSpace(comment)
+	if len(comment) == 0 {
+		return ""
+	}
+
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' && line[0] != ';' {
+			line = "; " + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,144 @@
+package ini
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func benchmarkKey(b *testing.B, value string) *Key {
+	m, err := Load([]byte("base = /srv\n[bench]\nkey = " + value + "\n"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	return m.Section("bench").Key("key")
+}
+
+func BenchmarkKeyString(b *testing.B) {
+	key := benchmarkKey(b, "plain value")
+	for b.Loop() {
+		_ = key.String()
+	}
+}
+
+func BenchmarkKeyStringInterpolated(b *testing.B) {
+	key := benchmarkKey(b, "%(base)s/data")
+	for b.Loop() {
+		_ = key.String()
+	}
+}
+
+func BenchmarkKeyStrings(b *testing.B) {
+	key := benchmarkKey(b, "alpha, beta, gamma, delta, epsilon")
+	for b.Loop() {
+		_ = key.Strings(",")
+	}
+}
+
+func BenchmarkKeyStringsEscaped(b *testing.B) {
+	key := benchmarkKey(b, `alpha\, beta, gamma, delta\, epsilon`)
+	for b.Loop() {
+		_ = key.Strings(",")
+	}
+}
+
+func TestMustStoresDefault(t *testing.T) {
+	for _, disable := range []bool{false, true} {
+		m, err := LoadSources(Options{DisableDefaultWriteback: disable}, []byte("port =\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Compute("url", func(s *Section) string { return "http://localhost:" + s.Key("port").String() })
+		if got := m.Section("").Key("url").String(); got != "http://localhost:" {
+			t.Fatalf("url = %q before default", got)
+		}
+
+		if got := m.Section("").Key("port").MustInt(8080); got != 8080 {
+			t.Errorf("MustInt = %d, want 8080", got)
+		}
+		want := "http://localhost:8080"
+		if disable {
+			want = "http://localhost:"
+		}
+		if got := m.Section("").Key("url").String(); got != want {
+			t.Errorf("disable %v: url = %q, want %q", disable, got, want)
+		}
+	}
+}
+
+func TestDecryptValues(t *testing.T) {
+	dec := DecryptorFunc(func(ct string) (string, error) {
+		if ct == "bad" {
+			return "", errors.New("bad ciphertext")
+		}
+		return strings.ToUpper(ct), nil
+	})
+	opts := Options{Decryptor: dec, DuplicateKeys: DuplicateShadow}
+	m, err := LoadSources(opts, []byte("a = ENC[one]\na = ENC[two]\nb = ENC[bad]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Section("").Key("a").ValueWithShadows(); !slices.Equal(got, []string{"ONE", "TWO"}) {
+		t.Errorf("values of a = %q, want [ONE TWO]", got)
+	}
+
+	b := m.Section("").Key("b")
+	if got := b.String(); got != "" {
+		t.Errorf("b = %q, want empty value", got)
+	}
+	if _, err = b.Expanded(); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expanded error = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestInferKind(t *testing.T) {
+	for val, want := range map[string]Kind{
+		"42":        KindInt,
+		"-1.5":      KindFloat,
+		"6.02e23":   KindFloat,
+		"nan":       KindString,
+		"inf":       KindString,
+		"-Infinity": KindString,
+		"0x1p-2":    KindString,
+		"true":      KindBool,
+		"1s":        KindDuration,
+	} {
+		if got := inferKind(val); got != want {
+			t.Errorf("inferKind(%q) = %v, want %v", val, got, want)
+		}
+	}
+}
+
+func TestTimeInWithoutZone(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*60*60)
+	m, err := LoadSources(Options{DefaultTimeLocation: loc}, []byte("local = 2024-05-01T10:30:00\nzoned = 2024-05-01T10:30:00Z\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec := m.Section("")
+	for name, want := range map[string]time.Time{
+		"local": time.Date(2024, 5, 1, 10, 30, 0, 0, loc),
+		"zoned": time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC),
+	} {
+		got, err := sec.Key(name).TimeIn(loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	var cfg struct {
+		Local time.Time `ini:"local"`
+	}
+	if err = sec.MapTo(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 5, 1, 10, 30, 0, 0, loc); !cfg.Local.Equal(want) {
+		t.Errorf("mapped local = %v, want %v", cfg.Local, want)
+	}
+}
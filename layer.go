@@ -0,0 +1,65 @@
+package ini
+
+import "cmp"
+
+// Conventional priorities of layers, from the lowest to the highest.
+const (
+	LayerDefaults = 0
+	LayerSystem   = 100
+	LayerUser     = 200
+	LayerEnv      = 300
+	LayerFlags    = 400
+)
+
+// Layer is a named group of data sources with a priority, see AsLayer.
+type Layer struct {
+	Name     string
+	Priority int
+}
+
+// layeredSource wraps a data source with its layer.
+type layeredSource struct {
+	source any
+	layer  *Layer
+}
+
+// AsLayer marks given data source as part of the named layer with given priority,
+// e.g. LayerUser. A value defined by a layer of higher priority replaces values of
+// layers of lower priority regardless of the order of appending, and is never
+// replaced by them. Sources of equal priority, including sources appended without
+// a layer which have priority zero, follow Options.DuplicateKeys. See Manager.Origin.
+func AsLayer(name string, priority int, source any) any {
+	return &layeredSource{source: source, layer: &Layer{Name: name, Priority: priority}}
+}
+
+// Origin returns the layer which supplied value of the key, the layer is a zero-value
+// when the key was not defined by a layered data source. It returns false when the
+// key does not exist, keys of parent sections are not taken into account.
+func (m *Manager) Origin(section, key string) (Layer, bool) {
+	sec, err := m.GetSection(section)
+	if err != nil {
+		return Layer{}, false
+	}
+	k, err := sec.GetKeyLocal(key)
+	if err != nil {
+		return Layer{}, false
+	}
+
+	defer m.readUnlock(m.readLock())
+	if k.layer == nil {
+		return Layer{}, true
+	}
+	return *k.layer, true
+}
+
+// compareLayers compares priorities of given layers, nil layer has priority zero.
+func compareLayers(a, b *Layer) int {
+	var pa, pb int
+	if a != nil {
+		pa = a.Priority
+	}
+	if b != nil {
+		pb = b.Priority
+	}
+	return cmp.Compare(pa, pb)
+}
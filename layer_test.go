@@ -0,0 +1,37 @@
+package ini
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLayersIgnoreLowerEdits(t *testing.T) {
+	user := AsLayer("user", LayerUser, []byte("a = 1\nb = x\n"))
+	defaults := AsLayer("defaults", LayerDefaults, []byte("a[] = 2\nb += y\nb = z\n"))
+	m, err := LoadSources(Options{AllowArrayKeys: true, AllowAppendOperator: true}, user, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec := m.Section("")
+	if got := sec.Key("a").ValueWithShadows(); !slices.Equal(got, []string{"1"}) {
+		t.Errorf("a = %q, want [1]", got)
+	}
+	if got := sec.Key("b").String(); got != "x" {
+		t.Errorf("b = %q, want x", got)
+	}
+	if layer, _ := m.Origin("", "b"); layer.Name != "user" {
+		t.Errorf("origin of b = %q, want user", layer.Name)
+	}
+}
+
+func TestLayersTakeOverPosition(t *testing.T) {
+	defaults := AsLayer("defaults", LayerDefaults, []byte("a = 1\n"))
+	user := AsLayer("user", LayerUser, []byte("\na = 2\n"))
+	m, err := LoadSources(Options{}, defaults, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, line := m.Section("").Key("a").Position(); line != 2 {
+		t.Errorf("line of a = %d, want 2", line)
+	}
+}
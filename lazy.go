@@ -0,0 +1,284 @@
+package ini
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"unicode"
+)
+
+// lazyBody is the text of keys of a section which is parsed on first access,
+// see Options.LazySections.
+type lazyBody struct {
+	mutex  sync.Mutex
+	data   []byte
+	source string
+	layer  *Layer
+	offset int // byte offset of data in the source
+	line   int // line number of the first line of data
+}
+
+// lazySections returns true if sections of data sources are indexed instead of parsed.
+// Errors of lazily parsed keys are only warnings, so options which fail the load on
+// malformed data disable it.
+func (m *Manager) lazySections() bool {
+	o := &m.options
+	strict := !o.ContinueOnError || o.DuplicateKeys == DuplicateError ||
+		o.OrphanKeys == OrphanKeysReject || o.ControlChars == ControlCharReject
+	return o.LazySections && !strict && !o.DotEnv && !o.AllowIncludeDirectives && !o.AllowNestedValues &&
+		!o.AllowPythonMultilineValues && o.Encoding == nil && o.ConcurrentSources < 2
+}
+
+// parseLazy parses keys before the first section header and the section headers of given
+// data, keys of each section are kept as text until the section is materialized.
+// Comments preceding a header are parsed along with it since they are the section comment.
+func (m *Manager) parseLazy(r io.Reader, source string, layer *Layer) (sections, keys int, err error) {
+	sc := &lazyScanner{
+		opts: &m.options,
+		buf:  bufio.NewReaderSize(r, max(m.options.ReaderBufferSize, minReaderBufferSize)),
+	}
+
+	var (
+		body, comments       bytes.Buffer // keys of current section, comment lines since the last key
+		commentOffset        int          // offset of the first line of comments
+		commentLine          int          // line of the first line of comments
+		bodyOffset, bodyLine = 0, 1       // position of the first line of body
+		preamble             = true       // whether no header was seen yet
+		current              *Section     // section of body, nil if discarded
+	)
+	// flush parses keys before the first header right away, and keeps keys
+	// of other sections to be parsed when the section is materialized.
+	flush := func() error {
+		if preamble {
+			_, n, k, err := m.parseChunk(body.Bytes(), source, layer, bodyOffset, bodyLine, nil)
+			sections, keys = sections+n, keys+k
+			return err
+		}
+		// Keys of discarded sections are discarded as well.
+		if current == nil || len(bytes.TrimSpace(body.Bytes())) == 0 {
+			return nil
+		}
+		current.lazy.Store(&lazyBody{
+			data:   bytes.Clone(body.Bytes()),
+			source: source,
+			layer:  layer,
+			offset: bodyOffset,
+			line:   bodyLine,
+		})
+		return nil
+	}
+
+	for {
+		kind, ok, err := sc.next()
+		if err != nil {
+			return sections, keys, err
+		}
+		if !ok {
+			break
+		}
+
+		switch kind {
+		case lazyComment:
+			if comments.Len() == 0 {
+				commentOffset, commentLine = sc.offset, sc.line
+			}
+			comments.Write(sc.text)
+		case lazyKey:
+			body.Write(comments.Bytes())
+			comments.Reset()
+			body.Write(sc.text)
+		case lazyHeader:
+			if err = flush(); err != nil {
+				return sections, keys, err
+			}
+			offset, line := sc.offset, sc.line
+			if comments.Len() > 0 {
+				offset, line = commentOffset, commentLine
+			}
+			comments.Write(sc.text)
+			sec, n, _, err := m.parseChunk(comments.Bytes(), source, layer, offset, line, nil)
+			sections += n
+			if err != nil {
+				return sections, keys, err
+			}
+			comments.Reset()
+			body.Reset()
+			current, preamble = sec, false
+			bodyOffset, bodyLine = sc.offset+len(sc.text), sc.line+1
+		}
+	}
+	body.Write(comments.Bytes())
+	return sections, keys, flush()
+}
+
+// lazyKind is the kind of a line found by lazyScanner.
+type lazyKind int8
+
+const (
+	lazyKey     lazyKind = iota // line of a key, including lines of values spanning lines
+	lazyComment                 // comment or blank line
+	lazyHeader                  // section header
+)
+
+// lazyScanner reads data line by line and tells section headers from other lines
+// without parsing keys, it follows the rules of parser.readValue for values spanning lines.
+type lazyScanner struct {
+	opts   *Options
+	buf    *bufio.Reader
+	long   []byte // buffer of lines longer than the reader buffer
+	text   []byte // current line including line break, valid until the next call
+	offset int    // byte offset of current line
+	line   int    // line number of current line
+	quote  string // closing quote of a value spanning lines
+	cont   bool   // whether the value continues on the next line
+}
+
+// next reads the next line and returns its kind, ok is false at the end of data.
+func (sc *lazyScanner) next() (kind lazyKind, ok bool, err error) {
+	sc.offset += len(sc.text)
+	text, err := sc.buf.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		sc.long = append(sc.long[:0], text...)
+		for err == bufio.ErrBufferFull {
+			text, err = sc.buf.ReadSlice('\n')
+			sc.long = append(sc.long, text...)
+		}
+		text = sc.long
+	}
+	if err != nil && err != io.EOF {
+		return 0, false, err
+	}
+	sc.text = text
+	if len(text) == 0 {
+		return 0, false, nil
+	}
+	sc.line++
+
+	trimmed := bytes.TrimSpace(text)
+	switch {
+	case len(sc.quote) > 0:
+		if bytes.LastIndex(text, []byte(sc.quote)) > -1 {
+			sc.quote = ""
+		}
+		return lazyKey, true, nil
+	case sc.cont:
+		sc.cont = len(trimmed) > 0 && trimmed[len(trimmed)-1] == '\\'
+		return lazyKey, true, nil
+	case len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == ';':
+		return lazyComment, true, nil
+	case trimmed[0] == '[':
+		// Keys following a malformed header continue the previous section when
+		// errors are tolerated, so the header is parsed along with the keys.
+		if _, _, err := readSectionName(trimmed); err == nil || !sc.opts.ContinueOnError {
+			return lazyHeader, true, nil
+		}
+		return lazyKey, true, nil
+	}
+	sc.scanValue(bytes.TrimLeftFunc(text, unicode.IsSpace))
+	return lazyKey, true, nil
+}
+
+// scanValue records whether the value of given key line continues on following lines.
+func (sc *lazyScanner) scanValue(line []byte) {
+	// Fast path for the most of lines which have neither quotes nor continuation.
+	if !bytes.ContainsAny(line, "\"`\\") {
+		return
+	}
+	_, offset, nameOnly, err := readKeyName(sc.opts, line)
+	if err != nil {
+		return
+	}
+	val := line
+	if !nameOnly {
+		val = bytes.TrimLeftFunc(line[offset:], unicode.IsSpace)
+	}
+	if len(val) == 0 {
+		return
+	}
+
+	var quote string
+	switch {
+	case len(val) > 3 && string(val[:3]) == `"""`:
+		quote = `"""`
+	case val[0] == '`':
+		quote = "`"
+	case sc.opts.UnescapeValueDoubleQuotes && val[0] == '"':
+		quote = `"`
+	}
+	if len(quote) > 0 {
+		if bytes.LastIndex(val[len(quote):], []byte(quote)) == -1 {
+			sc.quote = quote
+		}
+		return
+	}
+	val = bytes.TrimSpace(val)
+	sc.cont = !sc.opts.IgnoreContinuation && val[len(val)-1] == '\\'
+}
+
+// parseChunk parses data found at given byte offset and line of source. Keys before any
+// header are added to given section, or to the default section when nil. It returns the
+// section of the last accepted header, or given section if there is none, and the number
+// of headers and keys parsed.
+func (m *Manager) parseChunk(data []byte, source string, layer *Layer, offset, line int, section *Section) (*Section, int, int, error) {
+	p := newParser(bytes.NewReader(data), m, source)
+	defer p.release()
+	p.layer, p.offset, p.line, p.section = layer, offset, line-1, section
+	err := p.parse()
+	return p.section, p.sections, p.keys, err
+}
+
+// materialize parses keys of the section if it was indexed lazily. Errors are logged
+// and recorded as parse warnings since the load has already succeeded.
+func (s *Section) materialize() {
+	body := s.lazy.Load()
+	if body == nil {
+		return
+	}
+	body.mutex.Lock()
+	defer body.mutex.Unlock()
+	if s.lazy.Load() != body {
+		// Parsed by another goroutine meanwhile.
+		return
+	}
+
+	s.m.mutex.RLock()
+	dirty := s.dirty
+	s.m.mutex.RUnlock()
+
+	_, _, _, err := s.m.parseChunk(body.data, body.source, body.layer, body.offset, body.line, s)
+	if err != nil {
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			pe = &ParseError{Source: body.source, Err: err}
+		}
+		s.m.log(slog.LevelError, "ini: failed to parse section", slog.String("source", body.source),
+			slog.String("section", s.name), errorAttr(err))
+		s.m.addWarnings([]*ParseError{pe})
+	}
+
+	// Parsed keys are not modifications.
+	s.m.mutex.Lock()
+	s.dirty = dirty
+	s.m.mutex.Unlock()
+	s.lazy.Store(nil)
+}
+
+// materialize parses keys of all lazily indexed sections.
+func (m *Manager) materialize() {
+	if !m.options.LazySections {
+		return
+	}
+	locked := m.readLock()
+	sections := make([]*Section, 0, len(m.sectionList))
+	for _, name := range m.sectionList {
+		sections = append(sections, m.sections[name])
+	}
+	m.readUnlock(locked)
+
+	for _, sec := range sections {
+		sec.materialize()
+	}
+}
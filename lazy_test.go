@@ -0,0 +1,146 @@
+package ini
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const lazyData = `; preamble
+name = app
+
+# first
+[first] ; inline
+a = 1
+b = """multi
+[not a header]
+line"""
+c = one \
+[still c] \
+  two
+
+; comment of d
+d = ` + "`raw\n[not a header]`" + `
+
+# second
+[second]
+a = 2
+a = 3
+
+[empty]
+
+[first]
+e = 4
+# trailing
+`
+
+func TestLazySections(t *testing.T) {
+	for _, opts := range []Options{
+		{},
+		{DuplicateKeys: DuplicateShadow},
+		{IgnoreContinuation: true},
+		{UnescapeValueDoubleQuotes: true},
+	} {
+		opts.ContinueOnError = true
+		eager, err := LoadSources(opts, []byte(lazyData))
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.LazySections = true
+		lazy, err := LoadSources(opts, []byte(lazyData))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var want, got bytes.Buffer
+		if _, err = eager.WriteTo(&want); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = lazy.WriteTo(&got); err != nil {
+			t.Fatal(err)
+		}
+		if want.String() != got.String() {
+			t.Errorf("options %+v:\nwant:\n%s\ngot:\n%s", opts, want.String(), got.String())
+		}
+	}
+}
+
+func TestLazySectionsPosition(t *testing.T) {
+	m, err := LoadSources(Options{LazySections: true, ContinueOnError: true}, []byte(lazyData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []struct {
+		section, key string
+		line         int
+	}{
+		{"", "name", 2},
+		{"first", "d", 15},
+		{"second", "a", 20},
+		{"first", "e", 26},
+	} {
+		if _, line := m.Section(want.section).Key(want.key).Position(); line != want.line {
+			t.Errorf("line of %s.%s = %d, want %d", want.section, want.key, line, want.line)
+		}
+	}
+}
+
+// lazyBenchData returns data of given number of sections with 20 keys each.
+func lazyBenchData(sections int) []byte {
+	var b strings.Builder
+	for i := range sections {
+		fmt.Fprintf(&b, "# section %d\n[section%d]\n", i, i)
+		for j := range 20 {
+			fmt.Fprintf(&b, "key%d = value of key %d in section %d\n", j, j, i)
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+func benchmarkLoad(b *testing.B, lazy bool) {
+	data := lazyBenchData(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		m, err := LoadSources(Options{LazySections: lazy, ContinueOnError: true}, bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		// Access a single section as a typical lazy reader does.
+		_ = m.Section("section500").Key("key10").String()
+	}
+}
+
+func BenchmarkLoadEager(b *testing.B) { benchmarkLoad(b, false) }
+
+func BenchmarkLoadLazy(b *testing.B) { benchmarkLoad(b, true) }
+
+func TestLazySectionsPruneParents(t *testing.T) {
+	opts := Options{LazySections: true, ContinueOnError: true, CreateParentSections: true}
+	m, err := LoadSources(opts, []byte("[a.b]\ny = 2\n[a]\nx = 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.DeleteSection("a.b")
+	if !m.HasSection("a") {
+		t.Fatal("section a with lazily indexed keys was pruned")
+	}
+	if got := m.Section("a").Key("x").String(); got != "1" {
+		t.Errorf("a.x = %q, want 1", got)
+	}
+}
+
+func TestLazySectionsStrict(t *testing.T) {
+	data := []byte("[a]\nx = 1\nx = 2\n[b]\ny = \"\"\"unterminated\n")
+	for i, opts := range []Options{
+		{LazySections: true},
+		{LazySections: true, ContinueOnError: true, DuplicateKeys: DuplicateError},
+	} {
+		m, err := LoadSources(opts, data)
+		if err == nil && len(m.ParseWarnings()) == 0 {
+			t.Errorf("case %d: malformed data loaded without errors", i)
+		}
+	}
+}
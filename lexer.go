@@ -0,0 +1,307 @@
+package ini
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// TokenKind is the kind of a Token.
+type TokenKind int
+
+const (
+	// TokenComment is a comment, including the leading comment symbol.
+	TokenComment TokenKind = iota
+	// TokenSection is a section header, including the brackets.
+	TokenSection
+	// TokenKey is a key name, including surrounding quotes if any.
+	TokenKey
+	// TokenDelimiter is the delimiter between key and value.
+	TokenDelimiter
+	// TokenValue is a value, including surrounding quotes if any.
+	TokenValue
+	// TokenError is a piece of input that cannot be parsed.
+	TokenError
+)
+
+var tokenKindNames = []string{"Comment", "Section", "Key", "Delimiter", "Value", "Error"}
+
+// String returns name of the token kind.
+func (k TokenKind) String() string {
+	if k >= 0 && int(k) < len(tokenKindNames) {
+		return tokenKindNames[k]
+	}
+	return "TokenKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Token is a lexical element of INI data, whitespace is not emitted.
+type Token struct {
+	Kind TokenKind
+	// Text is the source text of the token, it may span multiple lines.
+	Text string
+	// Offset is the byte offset of the token in the source.
+	Offset int
+	// Line and Column are the 1-based position of the token in the source.
+	Line   int
+	Column int
+}
+
+// Lex splits given source into tokens with the default dialect rules.
+func Lex(src []byte) []Token {
+	return New(Options{}).Lex(src)
+}
+
+// Lex splits given source into tokens with the dialect rules configured for the manager.
+// Options.AllowPythonMultilineValues, AllowNestedValues and AllowIncludeDirectives are not
+// supported, such lines are lexed as keys on their own.
+func (m *Manager) Lex(src []byte) []Token {
+	l := &lexer{opts: &m.options, src: src, line: 1}
+	for l.pos < len(l.src) {
+		l.lexLine()
+	}
+	return l.tokens
+}
+
+type lexer struct {
+	opts   *Options
+	src    []byte
+	pos    int // offset of current position
+	line   int // line of current position
+	bol    int // offset of beginning of current line
+	tokens []Token
+}
+
+// emit adds a token for src[start:end] and moves to end.
+func (l *lexer) emit(kind TokenKind, start, end int) {
+	if end <= start {
+		return
+	}
+	l.tokens = append(l.tokens, Token{
+		Kind:   kind,
+		Text:   string(l.src[start:end]),
+		Offset: start,
+		Line:   l.line,
+		Column: start - l.bol + 1,
+	})
+	l.advance(end)
+}
+
+// advance moves to given offset and keeps track of lines.
+func (l *lexer) advance(to int) {
+	for ; l.pos < to; l.pos++ {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.bol = l.pos + 1
+		}
+	}
+}
+
+// eol returns offset of the end of current line, excluding line break.
+func (l *lexer) eol(from int) int {
+	i := bytes.IndexByte(l.src[from:], '\n')
+	if i < 0 {
+		return len(l.src)
+	}
+	end := from + i
+	if end > from && l.src[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
+// skipSpace returns offset of the first non-whitespace byte in current line.
+func (l *lexer) skipSpace(from, end int) int {
+	for from < end && (l.src[from] == ' ' || l.src[from] == '\t' || l.src[from] == '\f') {
+		from++
+	}
+	return from
+}
+
+// nextLine returns offset of the beginning of the line following given offset.
+func (l *lexer) nextLine(from int) int {
+	i := bytes.IndexByte(l.src[from:], '\n')
+	if i < 0 {
+		return len(l.src)
+	}
+	return from + i + 1
+}
+
+func (l *lexer) lexLine() {
+	end := l.eol(l.pos)
+	start := l.skipSpace(l.pos, end)
+	if start == end {
+		l.advance(min(end+1, len(l.src)))
+		return
+	}
+	l.advance(start)
+
+	switch c := l.src[start]; {
+	case c == '#' || c == ';':
+		l.emit(TokenComment, start, end)
+	case c == '[' && !l.opts.DotEnv:
+		// Dotenv data has no sections.
+		_, closeIdx, err := readSectionName(l.src[start:end])
+		if err != nil {
+			l.emit(TokenError, start, end)
+			break
+		}
+		l.emit(TokenSection, start, start+closeIdx+1)
+		if i := bytes.IndexAny(l.src[l.pos:end], "#;"); i > -1 {
+			l.emit(TokenComment, l.pos+i, end)
+		}
+	case l.opts.DotEnv:
+		l.lexDotEnv(start, end)
+	default:
+		l.lexKeyValue(start, end)
+	}
+	l.advance(min(l.eol(l.pos)+1, len(l.src)))
+}
+
+func (l *lexer) lexKeyValue(start, end int) {
+	_, offset, nameOnly, err := readKeyName(l.opts, l.src[start:end])
+	if err != nil {
+		l.emit(TokenError, start, end)
+		return
+	}
+	if nameOnly {
+		valEnd, commentStart := l.inlineComment(start, end)
+		l.emit(TokenKey, start, valEnd)
+		l.emit(TokenComment, commentStart, end)
+		return
+	}
+
+	delim := start + offset - 1
+	l.emit(TokenKey, start, start+len(strings.TrimRight(string(l.src[start:delim]), " \t")))
+	l.emit(TokenDelimiter, delim, delim+1)
+
+	valStart := l.skipSpace(delim+1, end)
+	if valStart == end {
+		return
+	}
+	l.advance(valStart)
+
+	// Quoted values may span multiple lines, see parser.readValue.
+	var quote string
+	switch {
+	case bytes.HasPrefix(l.src[valStart:], []byte(`"""`)) && valStart+3 < len(l.src):
+		quote = `"""`
+	case l.src[valStart] == '`':
+		quote = "`"
+	case l.opts.UnescapeValueDoubleQuotes && l.src[valStart] == '"':
+		quote = `"`
+	}
+	if len(quote) > 0 {
+		valEnd := l.closingQuote(valStart, quote)
+		if valEnd < 0 {
+			l.emit(TokenError, valStart, len(l.src))
+			return
+		}
+		lineEnd := l.eol(valEnd)
+		l.emit(TokenValue, valStart, valEnd)
+		if j := bytes.IndexAny(l.src[valEnd:lineEnd], "#;"); j > -1 {
+			l.emit(TokenComment, valEnd+j, lineEnd)
+		}
+		return
+	}
+
+	// Continuation lines are part of the value up to an empty line, including
+	// comment symbols, see parser.readContinuationLines.
+	if !l.opts.IgnoreContinuation && bytes.HasSuffix(bytes.TrimRight(l.src[valStart:end], " \t"), []byte(`\`)) {
+		for bytes.HasSuffix(bytes.TrimRight(l.src[valStart:end], " \t"), []byte(`\`)) {
+			from := l.nextLine(end)
+			next := l.eol(from)
+			if l.skipSpace(from, next) == next {
+				break
+			}
+			end = next
+		}
+		l.emit(TokenValue, valStart, valStart+len(bytes.TrimRight(l.src[valStart:end], " \t")))
+		return
+	}
+
+	valEnd, commentStart := l.inlineComment(valStart, end)
+	l.emit(TokenValue, valStart, valEnd)
+	l.emit(TokenComment, commentStart, end)
+}
+
+// closingQuote returns the end of a value surrounded by given quote which starts at
+// given offset, or -1 if the quote is not closed, see parser.readMultilines.
+func (l *lexer) closingQuote(valStart int, quote string) int {
+	from, end := valStart+len(quote), l.eol(valStart)
+	for {
+		if i := bytes.LastIndex(l.src[from:end], []byte(quote)); i > -1 {
+			return from + i + len(quote)
+		}
+		if from = l.nextLine(end); from == len(l.src) {
+			return -1
+		}
+		end = l.eol(from)
+	}
+}
+
+// lexDotEnv lexes a dotenv line, the export keyword is not emitted, see parser.readDotEnvValue.
+func (l *lexer) lexDotEnv(start, end int) {
+	start = end - len(trimExport(l.src[start:end]))
+	_, offset, _, err := readKeyName(l.opts, l.src[start:end])
+	if err != nil {
+		l.emit(TokenError, start, end)
+		return
+	}
+
+	delim := start + offset - 1
+	l.emit(TokenKey, start, start+len(bytes.TrimRight(l.src[start:delim], " \t")))
+	l.emit(TokenDelimiter, delim, delim+1)
+
+	valStart := l.skipSpace(delim+1, end)
+	if valStart == end {
+		return
+	}
+	l.advance(valStart)
+
+	// Quoted values may span multiple lines.
+	if quote := l.src[valStart]; quote == '"' || quote == '\'' {
+		i := closingQuote(string(l.src[valStart+1:]), quote)
+		if i < 0 {
+			l.emit(TokenError, valStart, len(l.src))
+			return
+		}
+		valEnd := valStart + 1 + i + 1
+		lineEnd := l.eol(valEnd)
+		l.emit(TokenValue, valStart, valEnd)
+		if j := bytes.IndexByte(l.src[valEnd:lineEnd], '#'); j > -1 {
+			l.emit(TokenComment, valEnd+j, lineEnd)
+		}
+		return
+	}
+
+	valEnd, commentStart := end, end
+	if i := bytes.Index(l.src[valStart:end], []byte(" #")); i > -1 {
+		commentStart = valStart + i + 1
+		valEnd = valStart + i
+	}
+	l.emit(TokenValue, valStart, valStart+len(bytes.TrimRight(l.src[valStart:valEnd], " \t")))
+	l.emit(TokenComment, commentStart, end)
+}
+
+// inlineComment returns the end of the value and start of the inline comment
+// within src[start:end] with respect of the comment options.
+func (l *lexer) inlineComment(start, end int) (int, int) {
+	text := string(l.src[start:end])
+	i := -1
+	if !l.opts.IgnoreInlineComment {
+		if l.opts.SpaceBeforeInlineComment {
+			if i = strings.Index(text, " #"); i == -1 {
+				i = strings.Index(text, " ;")
+			}
+			if i > -1 {
+				i++
+			}
+		} else {
+			i = strings.IndexAny(text, "#;")
+		}
+	}
+	if i < 0 {
+		return start + len(strings.TrimRight(text, " \t\r")), end
+	}
+	return start + len(strings.TrimRight(text[:i], " \t")), start + i
+}
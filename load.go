@@ -0,0 +1,32 @@
+package ini
+
+// Load returns a manager with default options and given data sources appended,
+// see Manager.Append for supported types of data sources.
+func Load(sources ...any) (*Manager, error) {
+	return LoadSources(Options{}, sources...)
+}
+
+// LooseLoad is like Load but nonexistent files are ignored, see Options.Loose.
+func LooseLoad(sources ...any) (*Manager, error) {
+	return LoadSources(Options{Loose: true}, sources...)
+}
+
+// LoadSources returns a manager with given options and data sources appended.
+func LoadSources(opts Options, sources ...any) (*Manager, error) {
+	m := New(opts)
+	if len(sources) == 0 {
+		return m, nil
+	}
+	if err := m.Append(sources[0], sources[1:]...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Empty returns a manager without any data source, options are optional.
+func Empty(opts ...Options) *Manager {
+	if len(opts) > 0 {
+		return New(opts[0])
+	}
+	return New(Options{})
+}
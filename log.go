@@ -0,0 +1,40 @@
+package ini
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// log emits a structured record through Options.Logger if set.
+func (m *Manager) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	l := m.options.Logger
+	if l == nil {
+		return
+	}
+	ctx := context.Background()
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	l.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// debug emits a debug record with source and line of the parser, it is also
+// passed to Options.DebugFunc as a single line of text.
+func (p *parser) debug(msg string, attrs ...slog.Attr) {
+	if fn := p.m.options.DebugFunc; fn != nil {
+		var b strings.Builder
+		b.WriteString(msg)
+		for _, a := range attrs {
+			fmt.Fprintf(&b, ", %s: %q", a.Key, a.Value.String())
+		}
+		fn(b.String())
+	}
+	p.m.log(slog.LevelDebug, msg, append(attrs, slog.String("source", p.source), slog.Int("line", p.line))...)
+}
+
+// errorAttr returns err as an attribute under the conventional "error" key.
+func errorAttr(err error) slog.Attr {
+	return slog.Any("error", err)
+}
@@ -1,21 +1,55 @@
 package ini
 
 import (
+	"errors"
 	"fmt"
+	"iter"
+	"log/slog"
+	"path"
+	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Manager struct {
-	options     Options
-	sources     []*dataSource
-	futures     []*dataSource
-	sections    map[string]*Section
-	sectionList []string
-	batch       atomic.Bool
-	mutex       Mutex
-	ValueMapper func(string) string
+	options      Options
+	sources      []*dataSource
+	futures      []*dataSource
+	sections     map[string]*Section
+	sectionList  []string
+	batch        atomic.Bool
+	closed       atomic.Bool
+	frozen       atomic.Bool
+	contributed  atomic.Bool
+	cleanups     []func() error
+	spilled      []string
+	spillCache   sync.Map // weak.Pointer[string] of spilled values read by file name
+	defaults     map[string]map[string]string
+	dirty        bool
+	events       *Events
+	files        fileCache
+	plaintexts   plaintextCache
+	stats        *statsCounter
+	phantoms     []Ref
+	phantomSet   map[Ref]struct{}
+	reads        sync.Map // Ref of keys read, see Options.TrackReads
+	deprecations map[Ref]*deprecation
+	computed     map[Ref]*computed
+	computeGen   atomic.Int64 // generation of computed values, see Compute
+	positions    []Position
+	warnings     []*ParseError
+	view         atomic.Pointer[View]
+	bindings     []func()
+	renames      []Rename
+	staging      bool               // definitions are recorded, see Manager.stage
+	staged       []stagedDefinition // definitions recorded while staging
+	newline      string
+	encoding     Encoding
+	mutex        Mutex
+	ValueMapper  func(string) string
 }
 
 func (m *Manager) Batch(fn func(m *Manager) error) error {
@@ -26,7 +60,17 @@ func (m *Manager) Batch(fn func(m *Manager) error) error {
 }
 
 // Append appends one or more data sources and reloads automatically.
-func (m *Manager) Append(source any, others ...any) error {
+// Data sources of type map[string]map[string]string hold keys by section name,
+// the default section is named "", and map[string]string holds keys of the default
+// section. Values of maps are taken as-is, the maps are copied when appended.
+func (m *Manager) Append(source any, others ...any) (err error) {
+	span := m.startSpan("ini.Append")
+	span.SetAttribute("sources", 1+len(others))
+	defer func() { span.End(err) }()
+
+	if m.rejectFrozen() {
+		return ErrFrozen
+	}
 	if err := m.append(source); err != nil {
 		return err
 	}
@@ -45,6 +89,9 @@ func (m *Manager) Append(source any, others ...any) error {
 }
 
 func (m *Manager) append(source any) error {
+	if m.closed.Load() {
+		return errManagerClosed
+	}
 	ds, err := parseDataSource(source)
 	if err != nil {
 		return err
@@ -62,75 +109,438 @@ func (m *Manager) flush() error {
 			s.Unlock()
 		}
 	}()
-	for len(m.futures) > 0 {
+	load := m.loader(m.futures)
+	for i := 0; len(m.futures) > 0; i++ {
 		s := m.futures[0]
-		if err := s.reload(m); err != nil {
+		if err := load(i); err != nil {
+			m.events.emit(Event{Type: EventSourceFailed, Source: s.name(), Err: err})
 			return err
 		}
+		m.events.emit(Event{Type: EventSourceAppended, Source: s.name()})
 		s.Lock()
 		m.futures = m.futures[1:]
+		m.mutex.Lock()
 		m.sources = append(m.sources, s)
+		m.mutex.Unlock()
 	}
+	m.markClean()
+	m.refreshView()
+	m.rebind()
 	return nil
 }
 
-// Reload reloads and parses all data sources.
-func (m *Manager) Reload() error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+// Reload reloads and parses all data sources, the loaded state is kept when it fails.
+func (m *Manager) Reload() (err error) {
+	locked := m.readLock()
+	sources := slices.Clone(m.sources)
+	m.readUnlock(locked)
 
-	clear(m.sections)
-	clear(m.sectionList)
-	m.sectionList = m.sectionList[:0]
+	span := m.startSpan("ini.Reload")
+	span.SetAttribute("sources", len(sources))
+	defer func() { span.End(err) }()
 
-	for _, s := range m.sources {
-		if err := s.reload(m); err != nil {
+	if m.rejectFrozen() {
+		return ErrFrozen
+	}
+
+	// Data sources are parsed into a staging manager, the current state is kept on failure.
+	opts := m.options
+	opts.Mutex = nil
+	next := New(opts)
+	next.stats, next.events, next.ValueMapper = m.stats, m.events, m.ValueMapper
+	load := next.loader(sources)
+	for i, s := range sources {
+		if err = load(i); err != nil {
+			m.events.emit(Event{Type: EventSourceFailed, Source: s.name(), Err: err})
+			if err := next.removeSpilled(); err != nil {
+				m.log(slog.LevelWarn, "ini: failed to remove spilled values", errorAttr(err))
+			}
 			return err
 		}
+		m.events.emit(Event{Type: EventSourceReloaded, Source: s.name()})
 	}
 
+	if m.options.KeepPreviousValues {
+		m.materialize()
+	}
+	m.mutex.Lock()
+	if m.options.KeepPreviousValues {
+		next.restorePrevious(m.snapshotValues())
+	}
+	spilled := m.spilled
+	m.sections, m.sectionList = next.sections, next.sectionList
+	for _, sec := range m.sections {
+		sec.m = m
+	}
+	m.positions = next.positions
+	m.warnings = next.warnings
+	m.spilled = next.spilled
+	m.spillCache.Clear()
+	m.renames = m.renames[:0]
+	m.newline = next.newline
+	m.encoding = next.encoding
+	m.contributed.Store(next.contributed.Load())
+	m.files.reset()
+	m.plaintexts.reset()
+	m.mutex.Unlock()
+	m.invalidateComputed()
+
+	if err := removeFiles(spilled); err != nil {
+		m.log(slog.LevelWarn, "ini: failed to remove spilled values", errorAttr(err))
+	}
+	m.markClean()
+	m.refreshView()
+	m.rebind()
+	m.stats.reloads.Add(1)
+	m.log(slog.LevelInfo, "ini: reloaded", slog.Int("sources", len(sources)))
+
 	return nil
 }
 
-// NewSection creates a new section.
-func (m *Manager) NewSection(name string) *Section {
-	if (m.options.Insensitive || m.options.InsensitiveSections) && len(name) > 0 {
-		name = strings.ToLower(name)
+// snapshotValues returns raw values of all keys grouped by section name.
+func (m *Manager) snapshotValues() map[string]map[string]string {
+	values := make(map[string]map[string]string, len(m.sections))
+	for name, sec := range m.sections {
+		values[name] = sec.values()
 	}
+	return values
+}
 
+// restorePrevious records values of given snapshot as previous values of keys,
+// the caller must hold the lock.
+func (m *Manager) restorePrevious(values map[string]map[string]string) {
+	for name, sec := range m.sections {
+		for kname, key := range sec.keys {
+			if val, ok := values[name][kname]; ok {
+				key.previous, key.hasPrevious = val, true
+			}
+		}
+	}
+}
+
+// CloseSources closes all reader sources retained by KeepOpen or Rewind.
+func (m *Manager) CloseSources() error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if slices.Contains(m.sectionList, name) {
-		return m.sections[name]
+	var errs []error
+	for _, s := range slices.Concat(m.sources, m.futures) {
+		if err := s.close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	m.sectionList = append(m.sectionList, name)
-	m.sections[name] = newSection(m, name)
+// Close stops background workers, closes retained sources and releases
+// pooled resources. It is safe to call Close more than once.
+func (m *Manager) Close() error {
+	if m.closed.Swap(true) {
+		return nil
+	}
+
+	m.mutex.Lock()
+	cleanups := m.cleanups
+	m.cleanups = nil
+	m.mutex.Unlock()
 
-	return m.sections[name]
+	var errs []error
+	// Run in reverse order, like deferred calls.
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := cleanups[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := m.CloseSources(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := m.removeSpilled(); err != nil {
+		errs = append(errs, err)
+	}
+
+	m.mutex.Lock()
+	m.sources = nil
+	m.futures = nil
+	m.mutex.Unlock()
+
+	return errors.Join(errs...)
 }
 
-// GetSection returns section by given name.
-func (m *Manager) GetSection(name string) (*Section, error) {
-	if len(name) > 0 && m.options.Insensitive || m.options.InsensitiveSections {
-		name = strings.ToLower(name)
+// onClose registers a cleanup function to be called by Close.
+func (m *Manager) onClose(fn func() error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.cleanups = append(m.cleanups, fn)
+}
+
+// SetDefaults registers default values of keys in given section, they are
+// used when keys are missing without being added to the section.
+func (m *Manager) SetDefaults(section string, defaults map[string]string) {
+	section = m.foldSection(section)
+	if m.rejectFrozen() {
+		return
 	}
 
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.defaults == nil {
+		m.defaults = make(map[string]map[string]string)
+	}
+	if m.defaults[section] == nil {
+		m.defaults[section] = make(map[string]string, len(defaults))
+	}
+	for name, val := range defaults {
+		m.defaults[section][m.foldKey(name)] = val
+	}
+}
+
+// defaultValue returns registered default value of given key.
+func (m *Manager) defaultValue(section, name string) string {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
+	return m.defaults[section][m.foldKey(name)]
+}
+
+// foldSection returns given section name as it is looked up, i.e. lowercased
+// when Options.Insensitive or Options.InsensitiveSections is set.
+func (m *Manager) foldSection(name string) string {
+	if m.options.Insensitive || m.options.InsensitiveSections {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// foldKey returns given key name as it is looked up, i.e. lowercased
+// when Options.Insensitive or Options.InsensitiveKeys is set.
+func (m *Manager) foldKey(name string) string {
+	if m.options.Insensitive || m.options.InsensitiveKeys {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// NewSection creates a new section.
+func (m *Manager) NewSection(name string) *Section {
+	return m.addSection(name, false)
+}
+
+// addSection returns section by given name, creating it if not exists. Sections created
+// implicitly as missing parents are marked so, and unmarked once created explicitly.
+func (m *Manager) addSection(name string, implicit bool) *Section {
+	display := name
+	name = m.foldSection(name)
+	if m.frozen.Load() {
+		if sec, err := m.GetSection(name); err == nil {
+			return sec
+		}
+		m.rejectFrozen()
+		return newSection(m, name)
+	}
+
+	if m.options.CreateParentSections {
+		if parent, ok := m.parentName(name); ok && len(parent) > 0 {
+			m.addSection(parent, true)
+		}
+	}
+
+	m.mutex.Lock()
+	if sec, ok := m.sections[name]; ok {
+		sec.implicit = sec.implicit && implicit
+		m.mutex.Unlock()
+		sec.materialize()
+		return sec
+	}
+
+	sec := newSection(m, name)
+	sec.implicit = implicit
+	if m.options.PreserveCase && display != name {
+		sec.display = display
+	}
+	m.sectionList = append(m.sectionList, name)
+	m.sections[name] = sec
+	m.dirty = true
+	m.mutex.Unlock()
+
+	m.events.emit(Event{Type: EventSectionAdded, Section: name, section: sec})
+	return sec
+}
+
+// DeleteSection deletes section by given name, it returns false if the section does not exist.
+func (m *Manager) DeleteSection(name string) bool {
+	name = m.foldSection(name)
+	if m.rejectFrozen() {
+		return false
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-	if slices.Contains(m.sectionList, name) {
-		return m.sections[name], nil
+	if !m.deleteSection(name) {
+		return false
+	}
+	if m.options.CreateParentSections {
+		m.pruneParents(name)
 	}
+	return true
+}
 
-	return nil, fmt.Errorf("section %q does not exist", name)
+func (m *Manager) deleteSection(name string) bool {
+	i := slices.Index(m.sectionList, name)
+	if i < 0 {
+		return false
+	}
+	m.sectionList = slices.Delete(m.sectionList, i, i+1)
+	for _, key := range m.sections[name].keys {
+		m.dropSpilled(key.spill)
+	}
+	delete(m.sections, name)
+	m.dirty = true
+	m.invalidateComputed()
+	return true
+}
+
+// pruneParents deletes implicitly created parent sections of given name which have
+// neither keys nor children, sections created explicitly are kept even if empty.
+func (m *Manager) pruneParents(name string) {
+	delim := m.options.ChildSectionDelimiter
+	for parent, ok := m.parentName(name); ok && len(parent) > 0; parent, ok = m.parentName(name) {
+		name = parent
+		sec, ok := m.sections[name]
+		// Keys of a lazily indexed section are not parsed yet, but it has some.
+		if !ok || !sec.implicit || len(sec.keyList) > 0 || sec.lazy.Load() != nil {
+			return
+		}
+		if slices.ContainsFunc(m.sectionList, func(s string) bool {
+			return strings.HasPrefix(s, name+delim)
+		}) {
+			return
+		}
+		m.deleteSection(name)
+	}
+}
+
+// GetSection returns section by given name.
+func (m *Manager) GetSection(name string) (*Section, error) {
+	name = m.foldSection(name)
+	locked := m.readLock()
+	sec, ok := m.sections[name]
+	m.readUnlock(locked)
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSectionNotFound, name)
+	}
+	sec.materialize()
+	return sec, nil
 }
 
 // HasSection returns true if the file contains a section with given name.
 func (m *Manager) HasSection(name string) bool {
-	section, _ := m.GetSection(name)
-	return section != nil
+	name = m.foldSection(name)
+	defer m.readUnlock(m.readLock())
+	_, ok := m.sections[name]
+	return ok
+}
+
+// IsPristine returns true if no data source has contributed any section or key,
+// e.g. all sources are zero-byte, whitespace-only or comment-only.
+// Empty sources are not an error and leave only an empty default section.
+func (m *Manager) IsPristine() bool {
+	return !m.contributed.Load()
+}
+
+// All returns an iterator over sections in order of definition,
+// the sections are snapshotted when iteration starts.
+func (m *Manager) All() iter.Seq[*Section] {
+	return func(yield func(*Section) bool) {
+		locked := m.readLock()
+		sections := make([]*Section, 0, len(m.sectionList))
+		for _, name := range m.sectionList {
+			sections = append(sections, m.sections[name])
+		}
+		m.readUnlock(locked)
+
+		for _, sec := range sections {
+			sec.materialize()
+			if !yield(sec) {
+				return
+			}
+		}
+	}
+}
+
+// Get returns key by dotted path, e.g. "server.tls.cert" resolves key "cert"
+// of section "server.tls" using ChildSectionDelimiter. Path without delimiter
+// refers to a key of the default section. A zero-value key is returned when not found.
+// See SplitPath for paths whose key names contain the delimiter.
+func (m *Manager) Get(path string) *Key {
+	section, name, key := m.resolve(path)
+	if key != nil {
+		return key
+	}
+	return m.Section(section).Key(name)
+}
+
+// Value returns string value of key by dotted path, see Get.
+func (m *Manager) Value(path string) (string, bool) {
+	_, _, key := m.resolve(path)
+	if key == nil {
+		return "", false
+	}
+	return key.String(), true
+}
+
+// SplitPath returns section and key name of given dotted path as resolved by Get:
+// the longest section name holding the key is tried first, so key names containing
+// the delimiter are supported as well, and the path is divided by the last delimiter
+// when no key is found, e.g. to create the key.
+func (m *Manager) SplitPath(path string) (section, name string) {
+	section, name, _ = m.resolve(path)
+	return section, name
+}
+
+// resolve splits dotted path into section and key name, see SplitPath,
+// the key is nil when not found.
+func (m *Manager) resolve(path string) (section, name string, key *Key) {
+	delim := m.options.ChildSectionDelimiter
+	for i := len(path); i > -1; i = strings.LastIndex(path[:i], delim) {
+		section, name = "", path
+		if i < len(path) {
+			section, name = path[:i], path[i+len(delim):]
+		}
+		if s, err := m.GetSection(section); err == nil {
+			if key, err := s.GetKey(name); err == nil {
+				return section, name, key
+			}
+		}
+	}
+	if i := strings.LastIndex(path, delim); i > -1 {
+		return path[:i], path[i+len(delim):], nil
+	}
+	return "", path, nil
+}
+
+// SectionsMatching returns sections whose names match given glob pattern,
+// e.g. "upstream:*", see path.Match for the pattern syntax.
+func (m *Manager) SectionsMatching(pattern string) []*Section {
+	var sections []*Section
+	for sec := range m.All() {
+		if ok, _ := path.Match(pattern, sec.name); ok {
+			sections = append(sections, sec)
+		}
+	}
+	return sections
+}
+
+// SectionsMatchingRegexp returns sections whose names match given regexp.
+func (m *Manager) SectionsMatchingRegexp(re *regexp.Regexp) []*Section {
+	var sections []*Section
+	for sec := range m.All() {
+		if re.MatchString(sec.name) {
+			sections = append(sections, sec)
+		}
+	}
+	return sections
 }
 
 // Section assumes named section exists and returns a zero-value when not.
@@ -138,6 +548,76 @@ func (m *Manager) Section(name string) *Section {
 	sec, err := m.GetSection(name)
 	if err != nil {
 		sec = newSection(m, name)
+		m.recordPhantom(Ref{Section: name})
 	}
 	return sec
 }
+
+// Key returns key by section and key name, it is a shortcut of m.Section(section).Key(name).
+func (m *Manager) Key(section, name string) *Key {
+	return m.Section(section).Key(name)
+}
+
+// KeyChain returns the first existing key with given name in given sections in order,
+// e.g. host-specific, env-specific and default section. Keys of parent sections are not
+// taken into account. A zero-value key of the first section is returned when not found.
+func (m *Manager) KeyChain(name string, sections ...string) *Key {
+	for _, section := range sections {
+		if sec, err := m.GetSection(section); err == nil {
+			if key, err := sec.GetKeyLocal(name); err == nil {
+				return key
+			}
+		}
+	}
+	if len(sections) == 0 {
+		return m.Key("", name)
+	}
+	return m.Key(sections[0], name)
+}
+
+// MustString returns default value if key value is empty.
+func (m *Manager) MustString(section, name string, defaultVal ...string) string {
+	return m.Section(section).MustString(name, defaultVal...)
+}
+
+// MustBool always returns value without error,
+// it returns false if error occurs.
+func (m *Manager) MustBool(section, name string, defaultVal ...bool) bool {
+	return m.Key(section, name).MustBool(defaultVal...)
+}
+
+// MustFloat64 always returns value without error,
+// it returns 0.0 if error occurs.
+func (m *Manager) MustFloat64(section, name string, defaultVal ...float64) float64 {
+	return m.Key(section, name).MustFloat64(defaultVal...)
+}
+
+// MustInt always returns value without error,
+// it returns 0 if error occurs.
+func (m *Manager) MustInt(section, name string, defaultVal ...int) int {
+	return m.Key(section, name).MustInt(defaultVal...)
+}
+
+// MustInt64 always returns value without error,
+// it returns 0 if error occurs.
+func (m *Manager) MustInt64(section, name string, defaultVal ...int64) int64 {
+	return m.Key(section, name).MustInt64(defaultVal...)
+}
+
+// MustUint always returns value without error,
+// it returns 0 if error occurs.
+func (m *Manager) MustUint(section, name string, defaultVal ...uint) uint {
+	return m.Key(section, name).MustUint(defaultVal...)
+}
+
+// MustUint64 always returns value without error,
+// it returns 0 if error occurs.
+func (m *Manager) MustUint64(section, name string, defaultVal ...uint64) uint64 {
+	return m.Key(section, name).MustUint64(defaultVal...)
+}
+
+// MustDuration always returns value without error,
+// it returns zero value if error occurs.
+func (m *Manager) MustDuration(section, name string, defaultVal ...time.Duration) time.Duration {
+	return m.Key(section, name).MustDuration(defaultVal...)
+}
@@ -0,0 +1,102 @@
+package ini
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReloadKeepsStateOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	if err := os.WriteFile(path, []byte("[s]\na = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadSources(Options{DuplicateKeys: DuplicateError}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(path, []byte("[s]\na = 2\n[t]\nb = 1\nb = 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Reload(); err == nil {
+		t.Fatal("reload of duplicate keys succeeded")
+	}
+	if got := m.Section("s").Key("a").String(); got != "1" {
+		t.Errorf("s.a = %q after failed reload, want 1", got)
+	}
+	if m.HasSection("t") {
+		t.Error("section t of failed reload was loaded")
+	}
+
+	if err = os.WriteFile(path, []byte("[s]\na = 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Section("s").Key("a").String(); got != "3" {
+		t.Errorf("s.a = %q, want 3", got)
+	}
+}
+
+func TestRedactExports(t *testing.T) {
+	m, err := LoadSources(Options{SensitiveKeys: []string{"*password"}}, []byte("[db]\nuser = app\npassword = secret\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.ToFlatMap(".")["db.password"]; got != RedactedValue {
+		t.Errorf("ToFlatMap db.password = %q, want %q", got, RedactedValue)
+	}
+	vals, err := NewProvider(m).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vals["db"].(map[string]any)["password"]; got != RedactedValue {
+		t.Errorf("Provider.Read db.password = %q, want %q", got, RedactedValue)
+	}
+	if got := vals["db"].(map[string]any)["user"]; got != "app" {
+		t.Errorf("Provider.Read db.user = %q, want app", got)
+	}
+}
+
+func TestDeleteSectionKeepsExplicitParents(t *testing.T) {
+	opts := Options{CreateParentSections: true}
+	m, err := LoadSources(opts, []byte("[a]\n[a.b]\n[c.d]\n[x.y.z]\n[x]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.DeleteSection("a.b")
+	m.DeleteSection("c.d")
+	m.DeleteSection("x.y.z")
+	var got []string
+	for sec := range m.All() {
+		got = append(got, sec.Name())
+	}
+	if want := []string{"", "a", "x"}; !slices.Equal(got, want) {
+		t.Errorf("sections = %q, want %q", got, want)
+	}
+}
+
+func TestToEnvCollision(t *testing.T) {
+	m, err := LoadSources(Options{}, []byte("[db]\nhost = localhost\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := m.ToEnv("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"APP_DB_HOST=localhost"}; !slices.Equal(env, want) {
+		t.Errorf("ToEnv = %q, want %q", env, want)
+	}
+
+	m, err = LoadSources(Options{}, []byte("[a]\nb.c = 1\n[a.b]\nc = 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.ToEnv(""); err == nil {
+		t.Error("colliding keys were rendered")
+	}
+}
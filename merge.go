@@ -0,0 +1,78 @@
+package ini
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Merge merges sections and keys of other manager into the manager,
+// existing keys are resolved by given policy. Nothing is merged when
+// MergeError is used and any key conflicts.
+func (m *Manager) Merge(other *Manager, policy MergePolicy) error {
+	if other == nil || other == m {
+		return nil
+	}
+	if m.rejectFrozen() {
+		return ErrFrozen
+	}
+
+	if policy == MergeError {
+		for src := range other.All() {
+			dst, err := m.GetSection(src.name)
+			if err != nil {
+				continue
+			}
+			for name := range src.All() {
+				if dst.hasOwnKey(name) {
+					return fmt.Errorf("ini: key %q of section %q conflicts", name, src.name)
+				}
+			}
+		}
+	}
+
+	for src := range other.All() {
+		dst := m.addSection(src.Name(), src.implicit)
+		locked := other.readLock()
+		comment := src.Comment
+		other.readUnlock(locked)
+		m.mutex.Lock()
+		if len(dst.Comment) == 0 {
+			dst.Comment = comment
+		}
+		m.mutex.Unlock()
+		for name, sk := range src.All() {
+			if !dst.hasOwnKey(name) {
+				dk := dst.NewKey(name, sk.rawValue())
+				copyKey(dk, sk)
+				continue
+			}
+			dk := dst.keys[m.foldKey(name)]
+			switch policy {
+			case MergeOverwrite:
+				dk.SetValue(sk.rawValue())
+				copyKey(dk, sk)
+			case MergeAppendShadow:
+				m.mutex.Lock()
+				for _, val := range sk.ValueWithShadows() {
+					dk.addShadow(val)
+				}
+				m.mutex.Unlock()
+				m.invalidateComputed()
+			}
+		}
+	}
+	return nil
+}
+
+// copyKey copies everything but the value of src key to dst key.
+func copyKey(dst, src *Key) {
+	dst.s.m.mutex.Lock()
+	defer dst.s.m.mutex.Unlock()
+
+	dst.Comment = src.Comment
+	dst.isBooleanType = src.isBooleanType
+	dst.isAutoIncrement = src.isAutoIncrement
+	dst.nestedValues = slices.Clone(src.nestedValues)
+	dst.shadows = slices.Clone(src.shadows)
+	dst.meta = copyMeta(dst.meta, src.meta)
+}
@@ -0,0 +1,63 @@
+package ini
+
+import "maps"
+
+// SetMeta stores a metadata value on the key, metadata is never written out.
+func (k *Key) SetMeta(name string, v any) {
+	if k.s.m.rejectFrozen() {
+		return
+	}
+
+	k.s.m.mutex.Lock()
+	defer k.s.m.mutex.Unlock()
+
+	if k.meta == nil {
+		k.meta = make(map[string]any)
+	}
+	k.meta[name] = v
+}
+
+// Meta returns the metadata value stored on the key by given name.
+func (k *Key) Meta(name string) (any, bool) {
+	k.s.m.mutex.RLock()
+	defer k.s.m.mutex.RUnlock()
+
+	v, ok := k.meta[name]
+	return v, ok
+}
+
+// SetMeta stores a metadata value on the section, metadata is never written out.
+func (s *Section) SetMeta(name string, v any) {
+	if s.m.rejectFrozen() {
+		return
+	}
+
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+
+	if s.meta == nil {
+		s.meta = make(map[string]any)
+	}
+	s.meta[name] = v
+}
+
+// Meta returns the metadata value stored on the section by given name.
+func (s *Section) Meta(name string) (any, bool) {
+	s.m.mutex.RLock()
+	defer s.m.mutex.RUnlock()
+
+	v, ok := s.meta[name]
+	return v, ok
+}
+
+// copyMeta copies metadata of src into dst, values of dst are replaced.
+func copyMeta(dst, src map[string]any) map[string]any {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]any, len(src))
+	}
+	maps.Copy(dst, src)
+	return dst
+}
@@ -3,42 +3,92 @@ package ini
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"regexp"
+	"log/slog"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 )
 
-const minReaderBufferSize = 4096
-
-var pythonMultiline = regexp.MustCompile(`^([\t\f ]+)(.*)`)
+const (
+	minReaderBufferSize  = 4096
+	maxPooledCommentSize = 64 << 10
+)
 
 type parser struct {
-	m   *Manager
-	buf *bufio.Reader
-
-	isEOF   bool
-	count   int
-	comment *bytes.Buffer
+	m      *Manager
+	buf    *bufio.Reader
+	source string
+
+	isEOF     bool
+	count     int
+	line      int
+	offset    int    // byte offset of the next read
+	lineStart int    // byte offset of the current line
+	text      []byte // raw text of the current line
+	column    int    // column of the first non-whitespace byte of the current line
+	keyLine   int    // line where the current key starts
+	keyColumn int    // column where the current key starts
+	keyText   []byte // raw text of the line where the current key starts
+	depth     int    // depth of nested include directives
+	detected  bool   // whether line ending was detected
+	comment   *bytes.Buffer
+	errs      []*ParseError
+	sections  int // number of section headers parsed
+	keys      int // number of key definitions parsed
+	layer     *Layer
+	section   *Section // section to continue, then section of the last accepted header, see parseLazy
+	// scan receives parsed entities instead of the manager, see Scan.
+	scan func(Event) error
 }
 
-func (p *parser) debug(format string, args ...any) {
-	if p.m.options.DebugFunc != nil {
-		p.m.options.DebugFunc(fmt.Sprintf(format, args...))
-	}
-}
+// Readers and comment buffers are pooled since data sources are parsed
+// again on every Reload.
+var (
+	readerPool  sync.Pool
+	commentPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+)
 
-func newParser(r io.Reader, m *Manager) *parser {
+func newParser(r io.Reader, m *Manager, source string) *parser {
 	size := max(m.options.ReaderBufferSize, minReaderBufferSize)
+	if m.options.Encoding != nil {
+		r = m.options.Encoding.Decode(r)
+	}
 
 	return &parser{
-		buf:     bufio.NewReaderSize(r, size),
+		buf:     newReader(r, size),
 		m:       m,
+		source:  source,
 		count:   1,
-		comment: &bytes.Buffer{},
+		comment: commentPool.Get().(*bytes.Buffer),
+	}
+}
+
+// newReader returns a pooled reader of given buffer size if any.
+func newReader(r io.Reader, size int) *bufio.Reader {
+	if br, ok := readerPool.Get().(*bufio.Reader); ok {
+		if br.Size() == size {
+			br.Reset(r)
+			return br
+		}
+		readerPool.Put(br)
+	}
+	return bufio.NewReaderSize(r, size)
+}
+
+// release returns buffers of the parser to pools, the parser must not be used afterwards.
+func (p *parser) release() {
+	p.buf.Reset(nil)
+	readerPool.Put(p.buf)
+	// Huge buffers are left to the garbage collector.
+	if p.comment.Cap() <= maxPooledCommentSize {
+		p.comment.Reset()
+		commentPool.Put(p.comment)
 	}
+	p.buf, p.comment = nil, nil
 }
 
 // BOM handles header of UTF-8, UTF-16 LE and UTF-16 BE's BOM format.
@@ -52,13 +102,21 @@ func (p *parser) BOM() error {
 	}
 
 	switch {
-	case mask[0] == 254 && mask[1] == 255:
-		fallthrough
-	case mask[0] == 255 && mask[1] == 254:
+	case mask[0] == 254 && mask[1] == 255, mask[0] == 255 && mask[1] == 254:
+		enc := UTF16BE
+		if mask[0] == 255 {
+			enc = UTF16LE
+		}
 		_, err = p.buf.Read(mask)
 		if err != nil {
 			return err
 		}
+		p.offset += len(mask)
+		// Decode UTF-16 unless data is decoded already by Options.Encoding.
+		if p.m.options.Encoding == nil {
+			p.buf = bufio.NewReaderSize(enc.Decode(p.buf), p.buf.Size())
+			p.m.detectEncoding(enc)
+		}
 	case mask[0] == 239 && mask[1] == 187:
 		mask, err := p.buf.Peek(3)
 		if err != nil && err != io.EOF {
@@ -71,6 +129,7 @@ func (p *parser) BOM() error {
 			if err != nil {
 				return err
 			}
+			p.offset += len(mask)
 		}
 	}
 	return nil
@@ -78,6 +137,15 @@ func (p *parser) BOM() error {
 
 func (p *parser) readUntil(delim byte) ([]byte, error) {
 	data, err := p.buf.ReadBytes(delim)
+	if len(data) > 0 {
+		p.line++
+	}
+	p.lineStart = p.offset
+	p.offset += len(data)
+	if !p.detected && len(data) > 0 && data[len(data)-1] == '\n' {
+		p.detected = true
+		p.m.detectLineEnding(data)
+	}
 	if err != nil {
 		if err == io.EOF {
 			p.isEOF = true
@@ -96,7 +164,74 @@ func cleanComment(in []byte) ([]byte, bool) {
 	return in[i:], true
 }
 
-func readKeyName(delimiters string, in []byte) (string, int, bool, error) {
+// readSectionName returns name of the section header and index of the closing bracket.
+// Names surrounded by double quotes may contain ']', and \" and \\ are unescaped,
+// e.g. ["weird ] name"].
+func readSectionName(line []byte) (string, int, error) {
+	if len(line) < 2 || line[1] != '"' {
+		closeIdx := bytes.LastIndexByte(line, ']')
+		if closeIdx == -1 {
+			return "", -1, fmt.Errorf("unclosed section: %s", bytes.TrimSpace(line))
+		}
+		return string(line[1:closeIdx]), closeIdx, nil
+	}
+
+	var name strings.Builder
+	for i := 2; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '\\':
+			if i+1 < len(line) && (line[i+1] == '"' || line[i+1] == '\\') {
+				i++
+				c = line[i]
+			}
+			name.WriteByte(c)
+		case '"':
+			closeIdx := bytes.IndexByte(line[i+1:], ']')
+			if closeIdx == -1 {
+				return "", -1, fmt.Errorf("unclosed section: %s", bytes.TrimSpace(line))
+			}
+			if len(bytes.TrimSpace(line[i+1:i+1+closeIdx])) > 0 {
+				return "", -1, fmt.Errorf("unexpected characters after quoted section name: %s", bytes.TrimSpace(line))
+			}
+			return name.String(), i + 1 + closeIdx, nil
+		default:
+			name.WriteByte(c)
+		}
+	}
+	return "", -1, fmt.Errorf("missing closing quote of section name: %s", bytes.TrimSpace(line))
+}
+
+// indexDelimiter returns index of the key-value delimiter in s, or -1 if there is none.
+func indexDelimiter(opts *Options, s string) int {
+	if !opts.PreferFirstDelimiter {
+		return indexAnyDelimiter(s, opts.KeyValueDelimiters, opts.KeyValueDelimiterRequiresSpace)
+	}
+	for _, delim := range opts.KeyValueDelimiters {
+		if i := indexAnyDelimiter(s, string(delim), opts.KeyValueDelimiterRequiresSpace); i > -1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexAnyDelimiter returns index of the first of given delimiters in s, delimiters
+// which are not followed by whitespace or end of line are skipped when space is set.
+func indexAnyDelimiter(s, delimiters string, space bool) int {
+	for from := 0; from < len(s); {
+		i := strings.IndexAny(s[from:], delimiters)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		if !space || i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\t' || s[i+1] == '\r' {
+			return i
+		}
+		from = i + 1
+	}
+	return -1
+}
+
+func readKeyName(opts *Options, in []byte) (string, int, bool, error) {
 	line := string(in)
 
 	// Check if key name surrounded by quotes.
@@ -118,12 +253,12 @@ func readKeyName(delimiters string, in []byte) (string, int, bool, error) {
 		// FIXME: fail case -> """"""name"""=value
 		pos := strings.Index(line[startIdx:], keyQuote)
 		if pos == -1 {
-			return "", -1, false, fmt.Errorf("missing closing key quote: %s", line)
+			return "", -1, false, fmt.Errorf("missing closing key quote: %s", strings.TrimSpace(line))
 		}
 		pos += startIdx
 
 		// Find key-value delimiter
-		i := strings.IndexAny(line[pos+startIdx:], delimiters)
+		i := indexDelimiter(opts, line[pos+startIdx:])
 		if i < 0 {
 			return "", -1, true, nil
 		}
@@ -131,12 +266,12 @@ func readKeyName(delimiters string, in []byte) (string, int, bool, error) {
 		return strings.TrimSpace(line[startIdx:pos]), endIdx + startIdx + 1, false, nil
 	}
 
-	endIdx = strings.IndexAny(line, delimiters)
+	endIdx = indexDelimiter(opts, line)
 	if endIdx < 0 {
 		return "", -1, true, nil
 	}
 	if endIdx == 0 {
-		return "", -1, false, fmt.Errorf("empty key name: %s", line)
+		return "", -1, false, fmt.Errorf("%w: %s", ErrEmptyKeyName, strings.TrimSpace(line))
 	}
 
 	return strings.TrimSpace(line[0:endIdx]), endIdx + 1, false, nil
@@ -179,6 +314,9 @@ func (p *parser) readContinuationLines(val string) (string, error) {
 		if len(next) == 0 {
 			break
 		}
+		if join := p.m.options.ContinuationJoin; len(join) > 0 {
+			val = strings.TrimRight(val, " \t") + join
+		}
 		val += next
 		if val[len(val)-1] != '\\' {
 			break
@@ -196,11 +334,11 @@ func hasSurroundedQuote(in string, quote byte) bool {
 		strings.IndexByte(in[1:], quote) == len(in)-2
 }
 
-func (p *parser) readValue(in []byte, bufferSize int) (string, error) {
+func (p *parser) readValue(in []byte) (string, error) {
 	line := strings.TrimLeftFunc(string(in), unicode.IsSpace)
 	if len(line) == 0 {
 		if p.m.options.AllowPythonMultilineValues && len(in) > 0 && in[len(in)-1] == '\n' {
-			return p.readPythonMultilines(line, bufferSize)
+			return p.readPythonMultilines(line)
 		}
 		return "", nil
 	}
@@ -264,159 +402,474 @@ func (p *parser) readValue(in []byte, bufferSize int) (string, error) {
 		line = strings.ReplaceAll(line, `\;`, ";")
 		line = strings.ReplaceAll(line, `\#`, "#")
 	} else if p.m.options.AllowPythonMultilineValues && lastChar == '\n' {
-		return p.readPythonMultilines(line, bufferSize)
+		return p.readPythonMultilines(line)
 	}
 
 	return line, nil
 }
 
-func (p *parser) readPythonMultilines(line string, bufferSize int) (string, error) {
-	parserBufferPeekResult, _ := p.buf.Peek(bufferSize)
-	peekBuffer := bytes.NewBuffer(parserBufferPeekResult)
-
-	for {
-		peekData, peekErr := peekBuffer.ReadBytes('\n')
-		if peekErr != nil && peekErr != io.EOF {
-			p.debug("readPythonMultilines: failed to peek with error: %v", peekErr)
-			return "", peekErr
-		}
-
-		p.debug("readPythonMultilines: parsing %q", string(peekData))
-
-		peekMatches := pythonMultiline.FindStringSubmatch(string(peekData))
-		p.debug("readPythonMultilines: matched %d parts", len(peekMatches))
-		for n, v := range peekMatches {
-			p.debug("   %d: %q", n, v)
-		}
-
-		// Return if not a Python multiline value.
-		if len(peekMatches) != 3 {
-			p.debug("readPythonMultilines: end of value, got: %q", line)
-			return line, nil
+// readPythonMultilines appends following lines indented by whitespace to given value,
+// the indentation is kept.
+func (p *parser) readPythonMultilines(line string) (string, error) {
+	for !p.isEOF {
+		next, err := p.buf.Peek(1)
+		if err != nil || (next[0] != ' ' && next[0] != '\t' && next[0] != '\f') {
+			break
 		}
-
-		// Advance the parser reader (buffer) in-sync with the peek buffer.
-		_, err := p.buf.Discard(len(peekData))
+		data, err := p.readUntil('\n')
 		if err != nil {
-			p.debug("readPythonMultilines: failed to skip to the end, returning error")
 			return "", err
 		}
+		line += "\n" + strings.TrimSuffix(string(data), "\n")
+	}
+	p.debug("readPythonMultilines: end of value", slog.Int("length", len(line)))
+	return line, nil
+}
+
+// fail wraps given error into a ParseError with source name and position of the current
+// line. It records the error and returns nil to continue parsing when Options.ContinueOnError
+// is set.
+func (p *parser) fail(err error) error {
+	return p.failAt(p.line, p.column, p.text, err)
+}
 
-		line += "\n" + peekMatches[0]
+// failKey is like fail with position of the line where the current key starts,
+// since its value may span multiple lines.
+func (p *parser) failKey(err error) error {
+	return p.failAt(p.keyLine, p.keyColumn, p.keyText, err)
+}
+
+// failAt is like fail with given position and raw text of the line.
+func (p *parser) failAt(line, column int, text []byte, err error) error {
+	pe := &ParseError{
+		Source: p.source,
+		Line:   line,
+		Column: column,
+		Text:   string(bytes.TrimRight(text, "\r\n")),
+		Err:    err,
+	}
+	if !p.m.options.ContinueOnError {
+		return pe
 	}
+	p.errs = append(p.errs, pe)
+	p.comment.Reset()
+	return nil
 }
 
-// parse parses data through an io.Reader.
-func (m *Manager) parse(reader io.Reader) (err error) {
-	p := newParser(reader, m)
-	if err = p.BOM(); err != nil {
-		return fmt.Errorf("BOM: %v", err)
+// addPosition records location of a parsed entity at given offset of current source.
+func (p *parser) addPosition(kind TokenKind, section, key string, offset, line int) {
+	if p.scan != nil {
+		return
 	}
+	p.m.addPosition(Position{
+		Kind:    kind,
+		Section: section,
+		Key:     key,
+		Source:  p.source,
+		Offset:  offset,
+		Line:    line,
+	})
+}
 
-	var name string // default section name to empty string
-	section := m.NewSection(name)
+// acceptKey returns the key name to use and false if the key should be discarded,
+// keys of discarded sections are always discarded.
+func (p *parser) acceptKey(skipSection bool, section, key string) (string, bool, error) {
+	if skipSection {
+		return key, false, nil
+	}
+	ref, err := p.asciiRef(Ref{Section: section, Key: key})
+	if err != nil {
+		return key, false, err
+	}
+	accepted, err := p.m.acceptKey(section, ref.Key)
+	return ref.Key, accepted, err
+}
 
-	var line []byte
+// asciiRef applies Options.ASCIINames to given ref, renames are not recorded when scanning
+// so memory use does not grow with size of the data.
+func (p *parser) asciiRef(ref Ref) (Ref, error) {
+	if p.scan != nil {
+		return p.m.transliterateRef(ref)
+	}
+	return p.m.asciiRef(ref)
+}
 
-	// NOTE: Iterate and increase `currentPeekSize` until
-	// the size of the parser buffer is found.
-	parserBufferSize := 0
-	// NOTE: Peek 4kb at a time.
-	currentPeekSize := minReaderBufferSize
+// newSection returns section by given name, sections are not added to the manager when scanning.
+func (p *parser) newSection(name string) *Section {
+	if p.scan != nil {
+		return newSection(p.m, name)
+	}
+	return p.m.NewSection(name)
+}
 
-	if m.options.AllowPythonMultilineValues {
-		for {
-			peekBytes, _ := p.buf.Peek(currentPeekSize)
-			peekBytesLength := len(peekBytes)
+// emit passes a parsed entity to the scan callback, followed by its inline comment if any.
+func (p *parser) emit(ev Event) error {
+	if ev.Type == EventScanKey {
+		ev.column, ev.text = p.keyColumn, p.keyText
+	}
+	if err := p.scan(ev); err != nil {
+		return err
+	}
+	comment := bytes.TrimSpace(p.comment.Bytes())
+	if len(comment) == 0 {
+		return nil
+	}
+	p.comment.Reset()
+	return p.scan(Event{Type: EventScanComment, Section: ev.Section, Value: string(comment), Line: ev.Line})
+}
 
-			if parserBufferSize >= peekBytesLength {
-				break
-			}
+// parse parses data through an io.Reader, source names where the data came from.
+// Keys are defined by given layer, which may be nil. It returns the number of
+// section headers and key definitions parsed.
+func (m *Manager) parse(reader io.Reader, source string, layer *Layer) (sections, keys int, err error) {
+	p := newParser(reader, m, source)
+	defer p.release()
+	p.layer = layer
+	err = p.parse()
+	return p.sections, p.keys, err
+}
 
-			currentPeekSize *= 2
-			parserBufferSize = peekBytesLength
-		}
+func (p *parser) parse() (err error) {
+	m := p.m
+	if err = p.BOM(); err != nil {
+		return fmt.Errorf("BOM: %v", err)
+	}
+
+	// Keys of lazily indexed sections continue the section of their header.
+	section, sawHeader := p.section, p.section != nil
+	if section == nil {
+		section = p.newSection("") // default section name to empty string
 	}
 
+	var line []byte
+	var lastRegularKey *Key
+	skipSection := false
+	isLastValueEmpty := false
+
 	for !p.isEOF {
 		line, err = p.readUntil('\n')
 		if err != nil {
 			return err
 		}
+		p.text = line
+
+		if m.options.AllowNestedValues && isLastValueEmpty && len(line) > 0 &&
+			(line[0] == ' ' || line[0] == '\t') {
+			if nested := bytes.TrimSpace(line); len(nested) > 0 {
+				value, err := applyControlCharPolicy(string(nested), m.options.ControlChars)
+				if err != nil {
+					p.column = len(line) - len(bytes.TrimLeftFunc(line, unicode.IsSpace)) + 1
+					if err = p.fail(fmt.Errorf("key %q: %w", lastRegularKey.name, err)); err != nil {
+						return err
+					}
+					continue
+				}
+				if p.scan != nil {
+					ev := Event{Type: EventScanKey, Section: section.name, Key: lastRegularKey.name, Value: value, Line: p.line, repeatable: true}
+					if err = p.emit(ev); err != nil {
+						return err
+					}
+					continue
+				}
+				if _, err = lastRegularKey.s.define(&definition{name: lastRegularKey.name, value: value, nested: true}); err != nil {
+					return err
+				}
+				continue
+			}
+		}
 
+		start := p.lineStart + len(line)
 		line = bytes.TrimLeftFunc(line, unicode.IsSpace)
 		if len(line) == 0 {
 			continue
 		}
+		start -= len(line)
+		p.column = start - p.lineStart + 1
 
 		// Comments
 		if line[0] == '#' || line[0] == ';' {
 			// Note: we do not care ending line break,
 			// it is needed for adding second line,
 			// so just clean it once at the end when set to value.
+			if p.scan != nil {
+				ev := Event{Type: EventScanComment, Section: section.name, Value: string(bytes.TrimSpace(line)), Line: p.line}
+				if err = p.emit(ev); err != nil {
+					return err
+				}
+				continue
+			}
 			p.comment.Write(line)
+			p.addPosition(TokenComment, section.name, "", start, p.line)
 			continue
 		}
 
-		// Section
-		if line[0] == '[' {
-			// Read to the next ']' (TODO: support quoted strings)
-			closeIdx := bytes.LastIndexByte(line, ']')
-			if closeIdx == -1 {
-				return fmt.Errorf("unclosed section: %s", line)
+		// Directives
+		if line[0] == '!' && m.options.AllowIncludeDirectives {
+			if err = p.directive(string(bytes.TrimSpace(line))); err != nil {
+				if err = p.fail(err); err != nil {
+					return err
+				}
 			}
+			continue
+		}
 
-			name := string(line[1:closeIdx])
-			section = m.NewSection(name)
+		// Section, dotenv data has no sections.
+		if line[0] == '[' && !m.options.DotEnv {
+			name, closeIdx, err := readSectionName(line)
+			if err != nil {
+				if err = p.fail(err); err != nil {
+					return err
+				}
+				continue
+			}
+			sawHeader = true
+
+			excluded := false
+			if m.options.Condition != nil {
+				var cond string
+				if name, cond = splitCondition(name); len(cond) > 0 {
+					excluded = !m.options.Condition(cond)
+				}
+			}
+			if m.options.GitSubsections {
+				name, err = m.gitSectionName(name)
+			}
+			accepted := false
+			if err == nil {
+				var ref Ref
+				ref, err = p.asciiRef(Ref{Section: name})
+				name = ref.Section
+			}
+			if err == nil && !excluded {
+				accepted, err = m.acceptSection(name)
+			}
+			if err != nil {
+				if err = p.fail(err); err != nil {
+					return err
+				}
+				accepted = false
+			}
+			if !accepted {
+				// Keys are discarded along with the section.
+				skipSection = true
+				p.comment.Reset()
+				isLastValueEmpty = false
+				continue
+			}
+			skipSection = false
+			section = p.newSection(name)
+			p.section = section
+			p.sections++
+			m.contributed.Store(true)
+			section.setPosition(p.source, p.line)
+			p.addPosition(TokenSection, name, "", start, p.line)
 
 			comment, has := cleanComment(line[closeIdx+1:])
 			if has {
 				p.comment.Write(comment)
+				p.addPosition(TokenComment, name, "", start+len(line)-len(comment), p.line)
 			}
 
-			section.Comment = strings.TrimSpace(p.comment.String())
-
 			// Reset auto-counter and comments
-			p.comment.Reset()
 			p.count = 1
+			isLastValueEmpty = false
+
+			if p.scan != nil {
+				// Inline comment is emitted along with the section.
+				if err = p.emit(Event{Type: EventScanSection, Section: name, Line: p.line}); err != nil {
+					return err
+				}
+				continue
+			}
+
+			section.Comment = strings.TrimSpace(p.comment.String())
+			p.comment.Reset()
 
 			continue
 		}
 
-		kname, offset, nameOnly, err := readKeyName(m.options.KeyValueDelimiters, line)
+		// Value may span multiple lines, so remember where the key starts.
+		keyLine := p.line
+		p.keyLine, p.keyColumn, p.keyText = p.line, p.column, p.text
+		var accepted bool
+		if m.options.DotEnv {
+			line = trimExport(line)
+		}
+		kname, offset, nameOnly, err := readKeyName(&m.options, line)
 		if err != nil {
-			return err
+			if err = p.failKey(err); err != nil {
+				return err
+			}
+			isLastValueEmpty = false
+			continue
+		}
+		if !sawHeader && !skipSection {
+			switch m.options.OrphanKeys {
+			case OrphanKeysReject:
+				if err = p.failKey(fmt.Errorf("key %q appears before any section header", kname)); err != nil {
+					return err
+				}
+				isLastValueEmpty = false
+				continue
+			case OrphanKeysSection:
+				if section.name != m.options.OrphanSection {
+					section = p.newSection(m.options.OrphanSection)
+				}
+			}
 		}
+		m.contributed.Store(true)
 		// Treat as boolean key when desired, and whole line is key name.
 		if nameOnly {
-			kname, err := p.readValue(line, parserBufferSize)
+			commentLen := p.comment.Len()
+			kname, err := p.readValue(line)
 			if err != nil {
+				if err = p.failKey(err); err != nil {
+					return err
+				}
+				isLastValueEmpty = false
+				continue
+			}
+			if kname, accepted, err = p.acceptKey(skipSection, section.name, kname); err != nil {
+				if err = p.failKey(err); err != nil {
+					return err
+				}
+				isLastValueEmpty = false
+				continue
+			} else if !accepted {
+				p.comment.Reset()
+				isLastValueEmpty = false
+				continue
+			}
+			if p.scan != nil {
+				isLastValueEmpty = false
+				if err = p.emit(Event{Type: EventScanKey, Section: section.name, Key: kname, Value: "true", Line: keyLine, repeatable: true}); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, err = section.define(&definition{
+				name:          kname,
+				source:        p.source,
+				line:          keyLine,
+				comment:       strings.TrimSpace(p.comment.String()),
+				inlineComment: strings.TrimSpace(string(p.comment.Bytes()[min(commentLen, p.comment.Len()):])),
+				boolean:       true,
+			}); err != nil {
 				return err
 			}
-			key := section.NewBooleanKey(kname)
-			key.Comment = strings.TrimSpace(p.comment.String())
+			p.addPosition(TokenKey, section.name, kname, start, keyLine)
 			p.comment.Reset()
+			isLastValueEmpty = false
 			continue
 		}
 
-		// Auto increment.
+		// Auto increment, a quoted "-" is taken literally.
 		isAutoIncr := false
-		if kname == "-" {
+		if kname == "-" && line[0] != '"' && line[0] != '`' {
 			isAutoIncr = true
 			kname = "#" + strconv.Itoa(p.count)
 			p.count++
 		}
 
-		value, err := p.readValue(line[offset:], parserBufferSize)
+		// Array keys, e.g. name[] = value.
+		isArray := false
+		if m.options.AllowArrayKeys && len(kname) > 2 && strings.HasSuffix(kname, "[]") {
+			isArray = true
+			kname = kname[:len(kname)-2]
+		}
+
+		// Append operator, e.g. name += value.
+		isAppend := false
+		if m.options.AllowAppendOperator && offset > 1 && line[offset-1] == '=' && line[offset-2] == '+' {
+			isAppend = true
+			kname = strings.TrimSpace(strings.TrimSuffix(kname, "+"))
+		}
+
+		// Inline comment is written to the comment buffer while reading the value.
+		commentLen := p.comment.Len()
+		var value string
+		if m.options.DotEnv {
+			value, err = p.readDotEnvValue(line[offset:])
+		} else {
+			value, err = p.readValue(line[offset:])
+		}
+		if err == nil {
+			value, err = applyControlCharPolicy(value, m.options.ControlChars)
+		}
 		if err != nil {
-			return err
+			if err = p.failKey(fmt.Errorf("key %q: %w", kname, err)); err != nil {
+				return err
+			}
+			isLastValueEmpty = false
+			continue
+		}
+		if kname, accepted, err = p.acceptKey(skipSection, section.name, kname); err != nil {
+			if err = p.failKey(err); err != nil {
+				return err
+			}
+			isLastValueEmpty = false
+			continue
+		} else if !accepted {
+			p.comment.Reset()
+			isLastValueEmpty = false
+			continue
+		}
+
+		if p.scan != nil {
+			lastRegularKey = newKey(section, kname, value)
+			isLastValueEmpty = len(value) == 0
+			if err = p.emit(Event{
+				Type: EventScanKey, Section: section.name, Key: kname, Value: value, Line: keyLine,
+				repeatable: isAutoIncr || isArray || isAppend,
+			}); err != nil {
+				return err
+			}
+			continue
 		}
 
-		key := section.NewKey(kname, value)
-		key.isAutoIncrement = isAutoIncr
-		key.Comment = strings.TrimSpace(p.comment.String())
+		key, err := section.define(&definition{
+			name:          kname,
+			value:         value,
+			layer:         p.layer,
+			source:        p.source,
+			line:          keyLine,
+			comment:       strings.TrimSpace(p.comment.String()),
+			inlineComment: strings.TrimSpace(string(p.comment.Bytes()[min(commentLen, p.comment.Len()):])),
+			autoIncrement: isAutoIncr,
+			array:         isArray,
+			append:        isAppend,
+		})
+		if errors.Is(err, errDuplicateKey) {
+			if err = p.failKey(err); err != nil {
+				return err
+			}
+			isLastValueEmpty = false
+			continue
+		} else if err != nil {
+			return err
+		}
 		p.comment.Reset()
+		if key == nil {
+			isLastValueEmpty = false
+			continue
+		}
+		p.keys++
+		p.addPosition(TokenKey, section.name, kname, start, keyLine)
+		lastRegularKey = key
+		isLastValueEmpty = len(value) == 0
 	}
 
+	if p.scan != nil {
+		errs := make([]error, len(p.errs))
+		for i, pe := range p.errs {
+			errs[i] = pe
+		}
+		return errors.Join(errs...)
+	}
+	for _, pe := range p.errs {
+		m.log(slog.LevelWarn, "ini: skipped malformed line", slog.String("source", pe.Source),
+			slog.Int("line", pe.Line), slog.Int("column", pe.Column), errorAttr(pe.Err))
+	}
+	m.addWarnings(p.errs)
 	return nil
 }
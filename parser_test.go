@@ -0,0 +1,142 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// benchData returns data of given number of sections with 10 commented keys each.
+func benchData(sections int) []byte {
+	var b strings.Builder
+	for i := range sections {
+		fmt.Fprintf(&b, "; section %d\n[section%d]\n", i, i)
+		for j := range 10 {
+			fmt.Fprintf(&b, "# key %d\nkey%d = value %d\n", j, j, j)
+		}
+	}
+	return []byte(b.String())
+}
+
+func BenchmarkParse(b *testing.B) {
+	data := benchData(10)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := Load(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReload(b *testing.B) {
+	data := benchData(10)
+	m, err := Load(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if err = m.Reload(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkReloadSources reloads many small sources, where a reader buffer
+// per source costs the most.
+func BenchmarkReloadSources(b *testing.B) {
+	sources := make([]any, 100)
+	for i := range sources {
+		sources[i] = fmt.Appendf(nil, "[source%d]\nkey = value\n", i)
+	}
+	m, err := Load(sources...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err = m.Reload(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	data := "[s]\n  key = one \\\n  two\x00\n"
+	_, err := LoadSources(Options{ControlChars: ControlCharReject}, []byte(data))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("error %v, want ParseError", err)
+	}
+	if pe.Line != 2 || pe.Column != 3 || pe.Text != "  key = one \\" {
+		t.Errorf("position %d:%d %q, want 2:3 of the key line", pe.Line, pe.Column, pe.Text)
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	data := []byte("[s]\na = 1\na = 2\n")
+	for _, tt := range []struct {
+		policy DuplicatePolicy
+		want   []string
+	}{
+		{DuplicateKeepFirst, []string{"1"}},
+		{DuplicateKeepLast, []string{"2"}},
+		{DuplicateShadow, []string{"1", "2"}},
+		{DuplicateError, nil},
+	} {
+		m, err := LoadSources(Options{DuplicateKeys: tt.policy}, data)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%v: duplicate key loaded", tt.policy)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.Section("s").Key("a").ValueWithShadows(); !slices.Equal(got, tt.want) {
+			t.Errorf("%v: a = %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestDuplicateKeysContinueOnError(t *testing.T) {
+	opts := Options{DuplicateKeys: DuplicateError, ContinueOnError: true}
+	m, err := LoadSources(opts, []byte("[s]\na = 1\na = 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.ParseWarnings()) != 1 {
+		t.Errorf("warnings = %v, want 1", m.ParseWarnings())
+	}
+	if got := m.Section("s").Key("a").String(); got != "1" {
+		t.Errorf("a = %q, want 1", got)
+	}
+}
+
+func TestScanTransliterate(t *testing.T) {
+	m := New(Options{ASCIINames: ASCIITransliterate})
+	p := newParser(strings.NewReader("[café]\nnaïve = 1\n"), m, "")
+	defer p.release()
+	var keys []string
+	p.scan = func(ev Event) error {
+		if ev.Type == EventScanKey {
+			keys = append(keys, ev.Section+"."+ev.Key)
+		}
+		return nil
+	}
+	if err := p.parse(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cafe.naive"}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+	// Renames are not recorded by Scan, so memory use does not grow with the data.
+	if renames := m.Renames(); len(renames) > 0 {
+		t.Errorf("renames recorded while scanning: %v", renames)
+	}
+}
@@ -0,0 +1,34 @@
+package ini
+
+// Ref refers to a section or a key in a section.
+type Ref struct {
+	Section string
+	// Key is empty when the reference is to a section.
+	Key string
+}
+
+// PhantomReads returns sections and keys which were read but never defined,
+// i.e. fabricated as zero-values by Manager.Section and Section.Key.
+func (m *Manager) PhantomReads() []Ref {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	refs := make([]Ref, len(m.phantoms))
+	copy(refs, m.phantoms)
+	return refs
+}
+
+// recordPhantom records a read of undefined section or key.
+func (m *Manager) recordPhantom(ref Ref) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.phantomSet[ref]; ok {
+		return
+	}
+	if m.phantomSet == nil {
+		m.phantomSet = make(map[Ref]struct{})
+	}
+	m.phantomSet[ref] = struct{}{}
+	m.phantoms = append(m.phantoms, ref)
+}
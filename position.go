@@ -0,0 +1,34 @@
+package ini
+
+import "slices"
+
+// Position is the location of a section header, key or comment line in a data source.
+type Position struct {
+	// Kind is one of TokenSection, TokenKey and TokenComment.
+	Kind TokenKind
+	// Section is the name of the section the entity belongs to.
+	Section string
+	// Key is the name of the key for TokenKey.
+	Key string
+	// Source is the name of the data source, e.g. file path.
+	Source string
+	// Offset is the byte offset of the entity in the data source.
+	Offset int
+	// Line is the 1-based line number of the entity in the data source.
+	Line int
+}
+
+// Positions returns locations of all parsed section headers, keys and comment lines
+// in order of parsing. Every definition of a duplicate key or section is included.
+func (m *Manager) Positions() []Position {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return slices.Clone(m.positions)
+}
+
+// addPosition records location of a parsed entity.
+func (m *Manager) addPosition(pos Position) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.positions = append(m.positions, pos)
+}
@@ -0,0 +1,53 @@
+package ini
+
+// Options returns the options preset for the dialect, profiles without specific
+// behavior return default options.
+func (p Profile) Options() Options {
+	switch p {
+	case ProfilePython:
+		return Options{
+			AllowPythonMultilineValues: true,
+			SpaceBeforeInlineComment:   true,
+		}
+	case ProfileMySQL:
+		// Docs: https://dev.mysql.com/doc/refman/8.0/en/option-files.html
+		return Options{
+			AllowBooleanKeys:       true,
+			AllowIncludeDirectives: true,
+			KeyValueDelimiters:     "=",
+		}
+	case ProfileGit:
+		// Docs: https://git-scm.com/docs/git-config#_syntax
+		return Options{
+			GitSubsections:             true,
+			InsensitiveKeys:            true,
+			AllowBooleanKeys:           true,
+			KeyValueDelimiters:         "=",
+			UnescapeValueDoubleQuotes:  true,
+			DuplicateKeys:              DuplicateShadow,
+			AllowDuplicateShadowValues: true,
+		}
+	case ProfileDotEnv:
+		return Options{
+			DotEnv:             true,
+			KeyValueDelimiters: "=",
+		}
+	case ProfileSystemd:
+		// Docs: https://www.freedesktop.org/software/systemd/man/systemd.syntax.html
+		return Options{
+			KeyValueDelimiters:         "=",
+			IgnoreInlineComment:        true,
+			PreserveSurroundedQuote:    true,
+			ContinuationJoin:           " ",
+			DuplicateKeys:              DuplicateShadow,
+			AllowDuplicateShadowValues: true,
+		}
+	case ProfileAWS:
+		// Docs: https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-files.html
+		return Options{
+			AllowNestedValues:  true,
+			KeyValueDelimiters: "=",
+		}
+	}
+	return Options{}
+}
@@ -0,0 +1,72 @@
+package ini
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProfileMySQL(t *testing.T) {
+	m, err := LoadSources(ProfileMySQL.Options(), "testdata/mysql/my.cnf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []struct {
+		section, key, value string
+	}{
+		{"client", "port", "3306"},
+		{"client", "default-character-set", "utf8mb4"},
+		{"mysqld", "user", "mysql"},
+		{"mysqld", "datadir", "/var/lib/mysql"},
+		{"mysqld", "bind-address", "127.0.0.1"},
+		{"mysqldump", "max_allowed_packet", "16M"},
+	} {
+		if got := m.Section(want.section).Key(want.key).String(); got != want.value {
+			t.Errorf("%s.%s = %q, want %q", want.section, want.key, got, want.value)
+		}
+	}
+
+	for _, ref := range []Ref{
+		{"mysqld", "skip-networking"},
+		{"mysqld", "skip-name-resolve"},
+		{"mysqld", "log-bin"},
+		{"mysqldump", "quick"},
+	} {
+		if ok, err := m.Section(ref.Section).Key(ref.Key).Bool(); err != nil || !ok {
+			t.Errorf("%s.%s = %v, %v, want boolean key", ref.Section, ref.Key, ok, err)
+		}
+	}
+
+	if m.Section("mysqld").HasKey("ignored") {
+		t.Error("file without .cnf extension was included")
+	}
+}
+
+func TestProfileNames(t *testing.T) {
+	data := []byte(`[default]
+region = us-east-1
+
+[profile dev]
+sso_session = corp
+
+[sso-session corp]
+sso_region = us-east-1
+
+[services local]
+s3 =
+  endpoint_url = http://localhost:9000
+
+[ci]
+aws_access_key_id = key
+`)
+	m, err := LoadSources(Options{AllowNestedValues: true}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.ProfileNames(), []string{"default", "dev", "ci"}; !slices.Equal(got, want) {
+		t.Errorf("ProfileNames() = %q, want %q", got, want)
+	}
+	if _, err = m.Profile("sso-session corp"); err == nil {
+		t.Error("sso-session section was returned as a profile")
+	}
+}
@@ -0,0 +1,99 @@
+package ini
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Provider adapts a Manager to the interfaces expected by popular
+// configuration frameworks, e.g. koanf.Provider and viper.ReadConfig.
+type Provider struct {
+	m *Manager
+}
+
+// NewProvider returns a Provider backed by given Manager.
+func NewProvider(m *Manager) *Provider {
+	return &Provider{m: m}
+}
+
+// ReadBytes is not supported, the Provider returns parsed values by Read.
+// It exists to satisfy the koanf.Provider interface.
+func (p *Provider) ReadBytes() ([]byte, error) {
+	return nil, errors.New("ini: provider does not support ReadBytes")
+}
+
+// Read returns all values as a nested map, keys of the default section
+// are placed at the top level and other sections are placed under their names,
+// child sections are nested under their parents, e.g. section "a.b" at out["a"]["b"].
+// An error is returned when a key and a section end up at the same place.
+// Values of sensitive keys are redacted unless Options.RevealSensitive is set.
+// It satisfies the koanf.Provider interface and can be passed to viper.MergeConfigMap.
+func (p *Provider) Read() (map[string]any, error) {
+	p.m.mutex.RLock()
+	names := make([]string, len(p.m.sectionList))
+	copy(names, p.m.sectionList)
+	p.m.mutex.RUnlock()
+
+	out := make(map[string]any)
+	for _, name := range names {
+		sec, err := p.m.GetSection(name)
+		if err != nil {
+			continue
+		}
+
+		vals := out
+		for _, part := range p.m.sectionPath(name) {
+			switch v := vals[part].(type) {
+			case nil:
+				child := make(map[string]any)
+				vals[part], vals = child, child
+			case map[string]any:
+				vals = v
+			default:
+				return nil, fmt.Errorf("ini: section %q conflicts with a key", sec.Name())
+			}
+		}
+		for _, key := range sec.Keys() {
+			if _, ok := vals[key.Name()].(map[string]any); ok {
+				return nil, fmt.Errorf("ini: key %q of section %q conflicts with a section", key.Name(), sec.Name())
+			}
+			vals[key.Name()] = key.redacted(key.expand())
+		}
+	}
+	return out, nil
+}
+
+// sectionPath returns names of ancestors of given section followed by its own name
+// relative to its parent, e.g. "a", "b" for section "a.b". It is empty for the default section.
+func (m *Manager) sectionPath(name string) []string {
+	var path []string
+	for len(name) > 0 {
+		parent, ok := m.parentName(name)
+		if !ok {
+			path = append(path, name)
+			break
+		}
+		path = append(path, name[len(parent)+len(m.options.ChildSectionDelimiter):])
+		name = parent
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// Reader returns all values encoded as JSON, it can be passed to
+// viper.ReadConfig after calling viper.SetConfigType("json").
+func (p *Provider) Reader() (io.Reader, error) {
+	vals, err := p.Read()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
@@ -0,0 +1,35 @@
+package ini
+
+// UnreadKeys returns keys defined in data sources which were never read since the
+// manager was created, in order of definition. Reads are tracked by name, so they are
+// kept across Reload. It returns nil unless Options.TrackReads is set.
+func (m *Manager) UnreadKeys() []Ref {
+	if !m.options.TrackReads {
+		return nil
+	}
+
+	var refs []Ref
+	for sec := range m.All() {
+		for _, key := range sec.Keys() {
+			if _, line := key.Position(); line == 0 {
+				continue
+			}
+			ref := Ref{Section: sec.name, Key: key.name}
+			if _, ok := m.reads.Load(ref); !ok {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// markRead records a read of the key when Options.TrackReads is set.
+func (k *Key) markRead() {
+	if !k.s.m.options.TrackReads {
+		return
+	}
+	ref := Ref{Section: k.s.name, Key: k.name}
+	if _, ok := k.s.m.reads.Load(ref); !ok {
+		k.s.m.reads.Store(ref, struct{}{})
+	}
+}
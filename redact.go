@@ -0,0 +1,75 @@
+package ini
+
+import (
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// RedactedValue replaces non-empty values of sensitive keys in exports.
+const RedactedValue = "*****"
+
+// SetSensitive marks or unmarks the key as sensitive, it takes precedence over
+// Options.SensitiveKeys. Values of sensitive keys are written as RedactedValue by
+// WriteTo, MarshalJSON, ToYAML, ToTOML, WriteDotEnv, ToFlatMap and Provider.Read
+// unless revealed.
+func (k *Key) SetSensitive(sensitive bool) {
+	if k.s.m.rejectFrozen() {
+		return
+	}
+
+	k.s.m.mutex.Lock()
+	defer k.s.m.mutex.Unlock()
+
+	k.sensitivity = -1
+	if sensitive {
+		k.sensitivity = 1
+	}
+}
+
+// IsSensitive returns true if the key was marked as sensitive by SetSensitive,
+// or its name matches any of Options.SensitiveKeys.
+func (k *Key) IsSensitive() bool {
+	defer k.s.m.readUnlock(k.s.m.readLock())
+	return k.isSensitive()
+}
+
+func (k *Key) isSensitive() bool {
+	if k.sensitivity != 0 {
+		return k.sensitivity > 0
+	}
+	if len(k.s.m.options.SensitiveKeys) == 0 {
+		return false
+	}
+	name := strings.ToLower(k.name)
+	for _, pattern := range k.s.m.options.SensitiveKeys {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns RedactedValue in place of non-empty val if the key is sensitive
+// and Options.RevealSensitive is not set.
+func (k *Key) redact(val string) string {
+	if len(val) == 0 || k.s.m.options.RevealSensitive || !k.isSensitive() {
+		return val
+	}
+	return RedactedValue
+}
+
+// redacted is like redact but takes the read lock, exports call it outside of WriteWith.
+func (k *Key) redacted(val string) string {
+	defer k.s.m.readUnlock(k.s.m.readLock())
+	return k.redact(val)
+}
+
+// LogValue implements slog.LogValuer, the value is redacted if the key is sensitive.
+func (k *Key) LogValue() slog.Value {
+	val := k.expand()
+	if len(val) > 0 && !k.s.m.options.RevealSensitive && k.IsSensitive() {
+		val = RedactedValue
+	}
+	return slog.StringValue(val)
+}
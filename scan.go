@@ -0,0 +1,16 @@
+package ini
+
+import "io"
+
+// Scan parses data from given reader and passes every section header, key and
+// comment to fn as EventScanSection, EventScanKey and EventScanComment in order,
+// without building a Manager, so memory use does not grow with size of the data.
+// Nested values are passed as additional EventScanKey of the same key, and
+// duplicate keys are passed as-is regardless of Options.DuplicateKeys.
+// Scanning stops at the first error returned by fn.
+func Scan(r io.Reader, opts Options, fn func(Event) error) error {
+	p := newParser(r, New(opts), "")
+	defer p.release()
+	p.scan = fn
+	return p.parse()
+}
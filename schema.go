@@ -0,0 +1,66 @@
+package ini
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Schema lists known key names by section name, "*" allows any key in the section.
+// Sections and keys not present in the schema are discarded while parsing,
+// or rejected when Options.RejectUnknown is set.
+type Schema map[string][]string
+
+// section returns known key names of given section.
+func (s Schema) section(name string, fold bool) ([]string, bool) {
+	if keys, ok := s[name]; ok || !fold {
+		return keys, ok
+	}
+	for sec, keys := range s {
+		if strings.EqualFold(sec, name) {
+			return keys, true
+		}
+	}
+	return nil, false
+}
+
+// hasKey returns true if given key is known in the section.
+func (s Schema) hasKey(section, key string, foldSection, foldKey bool) bool {
+	keys, ok := s.section(section, foldSection)
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(keys, func(name string) bool {
+		return name == "*" || name == key || foldKey && strings.EqualFold(name, key)
+	})
+}
+
+// acceptSection returns false if the section should be discarded.
+func (m *Manager) acceptSection(name string) (bool, error) {
+	if m.options.Schema == nil {
+		return true, nil
+	}
+	if _, ok := m.options.Schema.section(name, m.options.Insensitive || m.options.InsensitiveSections); ok {
+		return true, nil
+	}
+	if m.options.RejectUnknown {
+		return false, fmt.Errorf("unknown section %q", name)
+	}
+	return false, nil
+}
+
+// acceptKey returns false if the key should be discarded.
+func (m *Manager) acceptKey(section, key string) (bool, error) {
+	if m.options.Schema == nil {
+		return true, nil
+	}
+	foldSection := m.options.Insensitive || m.options.InsensitiveSections
+	foldKey := m.options.Insensitive || m.options.InsensitiveKeys
+	if m.options.Schema.hasKey(section, key, foldSection, foldKey) {
+		return true, nil
+	}
+	if m.options.RejectUnknown {
+		return false, fmt.Errorf("unknown key %q in section %q", key, section)
+	}
+	return false, nil
+}
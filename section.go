@@ -1,85 +1,183 @@
 package ini
 
 import (
+	"errors"
 	"fmt"
+	"iter"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 type Section struct {
-	m        *Manager
-	name     string
-	keys     map[string]*Key
-	keyList  []string
-	keysHash map[string]string
+	m       *Manager
+	name    string
+	keys    map[string]*Key
+	keyList []string
+	display string // name as written, see Options.PreserveCase
+	source  string
+	line    int
+	meta    map[string]any
+	dirty   bool
+	lazy    atomic.Pointer[lazyBody] // keys not parsed yet, see Options.LazySections
+	// implicit indicates whether the section was created only as a missing parent,
+	// see Options.CreateParentSections.
+	implicit bool
 	Comment  string
 }
 
 func newSection(m *Manager, name string) *Section {
 	return &Section{
-		m:        m,
-		name:     name,
-		keys:     make(map[string]*Key),
-		keyList:  make([]string, 0),
-		keysHash: make(map[string]string),
+		m:       m,
+		name:    name,
+		keys:    make(map[string]*Key),
+		keyList: make([]string, 0),
 	}
 }
 
-// Name returns name of Section.
+// Name returns name of Section, as written when Options.PreserveCase is set.
 func (s *Section) Name() string {
+	if len(s.display) > 0 {
+		return s.display
+	}
 	return s.name
 }
 
+// Position returns the data source and line number where the section was defined,
+// line is zero if the section was not parsed from any data source.
+func (s *Section) Position() (source string, line int) {
+	return s.source, s.line
+}
+
+// setPosition records where the section was first defined.
+func (s *Section) setPosition(source string, line int) {
+	if s.line == 0 {
+		s.source, s.line = source, line
+	}
+}
+
+// SetComment replaces comment of section, every line is normalized
+// to start with a comment symbol, "; " is added when missing.
+func (s *Section) SetComment(comment string) {
+	if s.m.rejectFrozen() {
+		return
+	}
+
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+
+	s.Comment = normalizeComment(comment)
+	s.dirty = true
+}
+
 // Parent returns the parent section.
 func (s *Section) Parent() (*Section, bool) {
-	if i := strings.LastIndex(s.name, s.m.options.ChildSectionDelimiter); i > -1 {
-		return s.m.Section(s.name[:i]), true
+	if parent, ok := s.m.parentName(s.name); ok {
+		return s.m.Section(parent), true
 	}
 	return nil, false
 }
 
+// parentName returns name of the parent section of given section name,
+// delimiters escaped by backslash are part of the name, e.g. [a\.b] has no parent.
+func (m *Manager) parentName(name string) (string, bool) {
+	delim := m.options.ChildSectionDelimiter
+	for end := len(name); end > 0; {
+		i := strings.LastIndex(name[:end], delim)
+		if i < 0 {
+			break
+		}
+		// Count preceding backslashes, odd number means the delimiter is escaped.
+		j := i
+		for j > 0 && name[j-1] == '\\' {
+			j--
+		}
+		if (i-j)%2 == 0 {
+			return name[:i], true
+		}
+		end = i
+	}
+	return "", false
+}
+
 // NewKey creates a new key to given section.
 func (s *Section) NewKey(name, value string) *Key {
-	if s.m.options.Insensitive || s.m.options.InsensitiveKeys {
-		name = strings.ToLower(name)
+	display := name
+	name = s.m.foldKey(name)
+	if s.m.frozen.Load() {
+		if key, err := s.GetKeyLocal(name); err == nil {
+			return key
+		}
+		s.m.rejectFrozen()
+		return newKey(s, name, value)
 	}
 
 	s.m.mutex.Lock()
-	defer s.m.mutex.Unlock()
-
 	if slices.Contains(s.keyList, name) {
+		defer s.m.mutex.Unlock()
 		return s.keys[name]
 	}
 
+	key := newKey(s, name, value)
+	if s.m.options.PreserveCase && display != name {
+		key.display = display
+	}
 	s.keyList = append(s.keyList, name)
-	s.keys[name] = newKey(s, name, value)
-	s.keysHash[name] = value
-
-	return s.keys[name]
+	s.keys[name] = key
+	s.dirty = true
+	s.m.mutex.Unlock()
+
+	s.m.events.emit(Event{
+		Type:    EventKeyAdded,
+		Section: s.name,
+		Key:     name,
+		Value:   value,
+		section: s,
+		key:     key,
+	})
+	return key
 }
 
 func (s *Section) NewBooleanKey(name string) *Key {
 	key := s.NewKey(name, "true")
-	key.isBooleanType = true
+	if !s.m.frozen.Load() {
+		key.isBooleanType = true
+	}
 	return key
 }
 
-// GetKey returns key in section by given name.
+// GetKey returns key in section by given name, keys of parent sections
+// are looked up when not found unless Options.DisableParentInheritance is set.
+// Deprecated keys resolve to their replacements, see Manager.Deprecate.
 func (s *Section) GetKey(name string) (*Key, error) {
-	s.m.mutex.RLock()
-	if s.m.options.Insensitive || s.m.options.InsensitiveKeys {
-		name = strings.ToLower(name)
+	name = s.m.foldKey(name)
+	if key, ok := s.m.replacement(s.name, name); ok {
+		return key, nil
 	}
+	if s.m.options.DisableParentInheritance {
+		key, err := s.GetKeyLocal(name)
+		if err != nil {
+			if key, ok := s.m.computedKey(s, name); ok {
+				return key, nil
+			}
+		}
+		return key, err
+	}
+
+	locked := s.m.readLock()
 	key := s.keys[name]
-	s.m.mutex.RUnlock()
+	s.m.readUnlock(locked)
 
 	if key == nil {
+		if key, ok := s.m.computedKey(s, name); ok {
+			return key, nil
+		}
 		// Check if it is a child-section.
 		sname := s.name
 		for {
-			if i := strings.LastIndex(sname, s.m.options.ChildSectionDelimiter); i > -1 {
-				sname = sname[:i]
+			if parent, ok := s.m.parentName(sname); ok {
+				sname = parent
 				sec, err := s.m.GetSection(sname)
 				if err != nil {
 					continue
@@ -88,11 +186,230 @@ func (s *Section) GetKey(name string) (*Key, error) {
 			}
 			break
 		}
-		return nil, fmt.Errorf("error when getting key of section %q: key %q not exists", s.name, name)
+		return nil, fmt.Errorf("%w: %q in section %q", ErrKeyNotFound, name, s.name)
+	}
+	return key, nil
+}
+
+// GetKeyLocal returns key in section by given name without looking up parent sections.
+func (s *Section) GetKeyLocal(name string) (*Key, error) {
+	name = s.m.foldKey(name)
+	locked := s.m.readLock()
+	key := s.keys[name]
+	s.m.readUnlock(locked)
+
+	if key == nil {
+		return nil, fmt.Errorf("%w: %q in section %q", ErrKeyNotFound, name, s.name)
+	}
+	return key, nil
+}
+
+// MergeFrom copies keys and comments from other section, existing keys
+// are only replaced when overwrite is true.
+func (s *Section) MergeFrom(other *Section, overwrite bool) {
+	if other == nil || other == s || s.m.rejectFrozen() {
+		return
+	}
+
+	for name, src := range other.All() {
+		dst, err := s.GetKeyLocal(name)
+		added := err != nil
+		if added {
+			dst = s.NewKey(name, src.rawValue())
+		} else if overwrite {
+			dst.SetValue(src.rawValue())
+		} else {
+			continue
+		}
+
+		locked := other.m.readLock()
+		comment, isBooleanType, isAutoIncrement := src.Comment, src.isBooleanType, src.isAutoIncrement
+		nestedValues, meta := slices.Clone(src.nestedValues), copyMeta(nil, src.meta)
+		other.m.readUnlock(locked)
+
+		s.m.mutex.Lock()
+		dst.Comment = comment
+		dst.isBooleanType = isBooleanType
+		if added {
+			dst.isAutoIncrement = isAutoIncrement
+		}
+		dst.nestedValues = nestedValues
+		dst.meta = copyMeta(dst.meta, meta)
+		s.m.mutex.Unlock()
+	}
+
+	locked := other.m.readLock()
+	comment := other.Comment
+	other.m.readUnlock(locked)
+
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+	if len(s.Comment) == 0 || overwrite && len(comment) > 0 {
+		s.Comment = comment
+	}
+}
+
+// setParsedValue replaces value of key while parsing,
+// values longer than Options.SpillThreshold are spilled.
+func (s *Section) setParsedValue(k *Key, value string) error {
+	var spill string
+	if t := s.m.options.SpillThreshold; t > 0 && len(value) > t {
+		var err error
+		if spill, err = s.m.spillValue(value); err != nil {
+			return err
+		}
+		value = ""
+	}
+
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+
+	s.m.dropSpilled(k.spill)
+	k.value = value
+	k.spill = spill
+	return nil
+}
+
+// errDuplicateKey is the cause of rejecting a duplicate key, see DuplicateError.
+var errDuplicateKey = errors.New("duplicate key")
+
+// definition is a key defined by a data source, see Section.define.
+type definition struct {
+	name, value            string
+	layer                  *Layer
+	source                 string
+	line                   int
+	comment, inlineComment string
+	autoIncrement          bool // named "-"
+	array                  bool // named name[]
+	append                 bool // defined by name += value
+	boolean                bool // defined by its name only
+	nested                 bool // indented value of an existing key
+}
+
+// define applies a key definition of a data source as parsing does, following layers,
+// array keys, the append operator and Options.DuplicateKeys. It returns nil key when
+// the definition is ignored in favour of a layer of higher priority, and an error
+// wrapping errDuplicateKey when it is rejected. Definitions are recorded while the
+// manager is staging, see Manager.absorb.
+func (s *Section) define(d *definition) (*Key, error) {
+	m := s.m
+	if d.nested {
+		key, err := s.GetKeyLocal(d.name)
+		if err != nil {
+			return nil, err
+		}
+		m.mutex.Lock()
+		key.addNestedValue(d.value)
+		m.mutex.Unlock()
+		m.record(s.name, d)
+		return key, nil
+	}
+	if d.boolean {
+		key := s.NewBooleanKey(d.name)
+		s.annotate(key, d)
+		m.record(s.name, d)
+		return key, nil
+	}
+
+	isNew := !s.hasOwnKey(d.name)
+	key, err := s.GetKeyLocal(d.name)
+	order := 0
+	if err == nil {
+		order = compareLayers(d.layer, key.layer)
+	}
+	value := d.value
+	switch {
+	case err != nil || d.autoIncrement:
+	case order < 0:
+		// Values of layers of higher priority are kept, whatever the kind of definition.
+		return nil, nil
+	case d.array:
+		// Values of array keys always accumulate.
+		m.mutex.Lock()
+		key.shadows = append(key.shadows, value)
+		m.mutex.Unlock()
+	case d.append:
+		if old := key.rawValue(); len(old) > 0 {
+			value = old + m.options.AppendSeparator + value
+		}
+		if err = s.setParsedValue(key, value); err != nil {
+			return nil, err
+		}
+	case order > 0:
+		if err = s.setParsedValue(key, value); err != nil {
+			return nil, err
+		}
+	default:
+		switch m.options.DuplicateKeys {
+		case DuplicateError:
+			return nil, fmt.Errorf("%w %q in section %q", errDuplicateKey, d.name, s.name)
+		case DuplicateKeepLast:
+			if err = s.setParsedValue(key, value); err != nil {
+				return nil, err
+			}
+		case DuplicateShadow:
+			m.mutex.Lock()
+			key.addShadow(value)
+			m.mutex.Unlock()
+		}
+	}
+
+	if m.options.SpillThreshold > 0 && len(value) > m.options.SpillThreshold {
+		if key, err = s.newSpilledKey(d.name, value); err != nil {
+			return nil, err
+		}
+	} else {
+		key = s.NewKey(d.name, value)
 	}
+	m.mutex.Lock()
+	key.isAutoIncrement = d.autoIncrement
+	key.isArray = key.isArray || d.array
+	key.isAppend = key.isAppend || isNew && d.append
+	if isNew || !d.autoIncrement && order > 0 {
+		// The key belongs to the layer which defined it last.
+		key.layer = d.layer
+		key.source, key.line = d.source, d.line
+	}
+	m.mutex.Unlock()
+	s.annotate(key, d)
+	m.record(s.name, d)
 	return key, nil
 }
 
+// annotate sets position and comments of a defined key.
+func (s *Section) annotate(k *Key, d *definition) {
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+	k.setPosition(d.source, d.line)
+	k.Comment = d.comment
+	k.inlineComment = d.inlineComment
+}
+
+// KeysHash returns raw values of keys by name,
+// keys of parent sections are not taken into account.
+func (s *Section) KeysHash() map[string]string {
+	defer s.m.readUnlock(s.m.readLock())
+	return s.values()
+}
+
+// values returns raw values of keys by name, the caller must hold the lock.
+func (s *Section) values() map[string]string {
+	values := make(map[string]string, len(s.keys))
+	for name, key := range s.keys {
+		values[name] = key.rawValue()
+	}
+	return values
+}
+
+// hasOwnKey returns true if section itself contains a key with given name,
+// keys of parent sections are not taken into account.
+func (s *Section) hasOwnKey(name string) bool {
+	defer s.m.readUnlock(s.m.readLock())
+	_, ok := s.keys[s.m.foldKey(name)]
+	return ok
+}
+
 // HasKey returns true if section contains a key with given name.
 func (s *Section) HasKey(name string) bool {
 	key, _ := s.GetKey(name)
@@ -101,10 +418,9 @@ func (s *Section) HasKey(name string) bool {
 
 // HasValue returns true if section contains given raw value.
 func (s *Section) HasValue(value string) bool {
-	s.m.mutex.RLock()
-	defer s.m.mutex.RUnlock()
+	defer s.m.readUnlock(s.m.readLock())
 	for _, k := range s.keys {
-		if value == k.value {
+		if value == k.rawValue() {
 			return true
 		}
 	}
@@ -117,20 +433,105 @@ func (s *Section) Key(name string) *Key {
 	if err != nil {
 		// It's OK here because the only possible error is empty key name,
 		// but if it's empty, this piece of code won't be executed.
-		key = newKey(s, name, "")
+		key = newKey(s, name, s.m.defaultValue(s.name, name))
+		s.m.recordPhantom(Ref{Section: s.name, Key: name})
 	}
 	return key
 }
 
 // Keys returns list of keys of section.
 func (s *Section) Keys() []*Key {
+	defer s.m.readUnlock(s.m.readLock())
 	keys := make([]*Key, len(s.keyList))
-	for i := range s.keyList {
-		keys[i] = s.Key(s.keyList[i])
+	for i, name := range s.keyList {
+		keys[i] = s.keys[name]
+	}
+	return keys
+}
+
+// KeysWithPrefix returns list of keys whose names start with given prefix,
+// keys of parent sections are not taken into account.
+func (s *Section) KeysWithPrefix(prefix string) []*Key {
+	prefix = s.m.foldKey(prefix)
+	defer s.m.readUnlock(s.m.readLock())
+
+	var keys []*Key
+	for _, name := range s.keyList {
+		if strings.HasPrefix(name, prefix) {
+			keys = append(keys, s.keys[name])
+		}
 	}
 	return keys
 }
 
+// DeleteKey deletes key by given name, it returns false if the key does not exist.
+func (s *Section) DeleteKey(name string) bool {
+	name = s.m.foldKey(name)
+	if s.m.rejectFrozen() {
+		return false
+	}
+
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+
+	key, ok := s.keys[name]
+	if !ok {
+		return false
+	}
+	s.m.dropSpilled(key.spill)
+	delete(s.keys, name)
+	s.keyList = slices.DeleteFunc(s.keyList, func(n string) bool { return n == name })
+	s.dirty = true
+	s.m.invalidateComputed()
+	return true
+}
+
+// DeleteKeysWithPrefix deletes keys whose names start with given prefix
+// and returns the number of deleted keys.
+func (s *Section) DeleteKeysWithPrefix(prefix string) int {
+	prefix = s.m.foldKey(prefix)
+	if s.m.rejectFrozen() {
+		return 0
+	}
+
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+
+	n := len(s.keyList)
+	s.keyList = slices.DeleteFunc(s.keyList, func(name string) bool {
+		if !strings.HasPrefix(name, prefix) {
+			return false
+		}
+		s.m.dropSpilled(s.keys[name].spill)
+		delete(s.keys, name)
+		return true
+	})
+	if len(s.keyList) < n {
+		s.dirty = true
+		s.m.invalidateComputed()
+	}
+	return n - len(s.keyList)
+}
+
+// All returns an iterator over key names and keys in order of definition,
+// the keys are snapshotted when iteration starts. Names are given by Key.Name.
+func (s *Section) All() iter.Seq2[string, *Key] {
+	return func(yield func(string, *Key) bool) {
+		locked := s.m.readLock()
+		keys := make([]*Key, 0, len(s.keyList))
+		for _, name := range s.keyList {
+			keys = append(keys, s.keys[name])
+		}
+		s.m.readUnlock(locked)
+
+		for _, key := range keys {
+			if !yield(key.Name(), key) {
+				return
+			}
+		}
+	}
+}
+
 // String returns string representation of value.
 func (s *Section) String(name string) string {
 	return s.Key(name).String()
@@ -187,6 +588,18 @@ func (s *Section) Time(name string) (time.Time, error) {
 	return s.Key(name).Time()
 }
 
+// TimeFormatIn parses with given format and returns time.Time type value,
+// given location is used when the value lacks time zone information.
+func (s *Section) TimeFormatIn(name string, format string, loc *time.Location) (time.Time, error) {
+	return s.Key(name).TimeFormatIn(format, loc)
+}
+
+// TimeIn parses with RFC3339 format and returns time.Time type value,
+// given location is used when the value lacks time zone information.
+func (s *Section) TimeIn(name string, loc *time.Location) (time.Time, error) {
+	return s.Key(name).TimeIn(loc)
+}
+
 // MustString returns default value if key value is empty.
 func (s *Section) MustString(name string, defaultVal ...string) string {
 	if len(defaultVal) > 0 {
@@ -332,6 +745,18 @@ func (s *Section) Strings(name string, delim string) []string {
 	return s.Key(name).Strings(delim)
 }
 
+// StringsWithQuotes returns list of string divided by given delimiter,
+// elements surrounded by double quotes may contain the delimiter.
+func (s *Section) StringsWithQuotes(name string, delim string) []string {
+	return s.Key(name).StringsWithQuotes(delim)
+}
+
+// MustStrings always returns value without error,
+// it returns default value if the list is empty.
+func (s *Section) MustStrings(name string, delim string, defaultVal []string) []string {
+	return s.Key(name).MustStrings(delim, defaultVal)
+}
+
 // Float64s returns list of float64 divided by given delimiter. Any invalid input will be treated as zero value.
 func (s *Section) Float64s(name string, delim string) []float64 {
 	return s.Key(name).Float64s(delim)
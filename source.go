@@ -5,11 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
+	"strings"
 	"sync/atomic"
+	"time"
 )
 
-var errSourceLocked = errors.New("ini: the data source was locked")
+var (
+	// ErrSourceLocked is returned when opening a data source which is being read.
+	ErrSourceLocked  = errors.New("ini: the data source was locked")
+	errManagerClosed = errors.New("ini: the manager was closed")
+)
 
 type DataSource interface {
 	Open() (io.ReadCloser, error)
@@ -23,6 +34,29 @@ type dataSource struct {
 	path       string
 	source     DataSource
 	factory    func() (io.ReadCloser, error)
+	values     map[string]map[string]string
+	keepOpen   bool
+	rewind     bool
+	layer      *Layer
+}
+
+// retainedSource wraps a data source with its retention settings.
+type retainedSource struct {
+	source   any
+	keepOpen bool
+	rewind   bool
+}
+
+// KeepOpen marks given reader source not to be closed after parsing,
+// it stays open until Manager.CloseSources is called.
+func KeepOpen(source any) any {
+	return &retainedSource{source: source, keepOpen: true}
+}
+
+// Rewind marks given reader source to be seeked back to the start
+// before each reload, it implies KeepOpen and requires an io.Seeker.
+func Rewind(source any) any {
+	return &retainedSource{source: source, keepOpen: true, rewind: true}
 }
 
 func (s *dataSource) Lock() {
@@ -35,7 +69,7 @@ func (s *dataSource) Unlock() {
 
 func (s *dataSource) Open() (io.ReadCloser, error) {
 	if atomic.LoadInt32(&s.lock) == 1 {
-		return nil, errSourceLocked
+		return nil, ErrSourceLocked
 	}
 	if s.readCloser != nil {
 		return s.readCloser, nil
@@ -56,36 +90,215 @@ func (s *dataSource) Open() (io.ReadCloser, error) {
 }
 
 func (s *dataSource) reload(m *Manager) error {
+	if s.values != nil {
+		span := m.startSpan("ini.Parse")
+		span.SetAttribute("source", s.name())
+		start := time.Now()
+		sections, keys, err := s.loadValues(m)
+		locked := m.readLock()
+		span.SetAttribute("sections", len(m.sectionList))
+		m.readUnlock(locked)
+		span.End(err)
+		if err != nil {
+			m.log(slog.LevelError, "ini: failed to parse source", slog.String("source", s.name()), errorAttr(err))
+		}
+		m.recordParse(s, SourceStats{Source: s.name(), Duration: time.Since(start), Sections: sections, Keys: keys, Err: err})
+		return err
+	}
+
 	rc, err := s.Open()
 	if err != nil {
 		// In loose mode, we create an empty default section for nonexistent files.
 		if os.IsNotExist(err) && m.options.Loose {
 			return nil
 		}
-		if errors.Is(err, errSourceLocked) {
+		if errors.Is(err, ErrSourceLocked) {
 			return nil
 		}
 		return err
 	}
-	defer rc.Close()
-	return m.parse(rc)
+	if s.rewind {
+		if err = s.seekStart(); err != nil {
+			return err
+		}
+	}
+	if !s.keepOpen {
+		defer rc.Close()
+	}
+
+	span := m.startSpan("ini.Parse")
+	span.SetAttribute("source", s.name())
+	start := time.Now()
+	cr := &countingReader{r: rc}
+	var parsed, keys int
+	if m.lazySections() {
+		parsed, keys, err = m.parseLazy(cr, s.name(), s.layer)
+	} else {
+		parsed, keys, err = m.parse(cr, s.name(), s.layer)
+	}
+	span.SetAttribute("bytes", cr.n)
+	locked := m.readLock()
+	sections := len(m.sectionList)
+	m.readUnlock(locked)
+	span.SetAttribute("sections", sections)
+	span.End(err)
+	if err != nil {
+		m.log(slog.LevelError, "ini: failed to parse source", slog.String("source", s.name()), errorAttr(err))
+	} else {
+		m.log(slog.LevelDebug, "ini: parsed source", slog.String("source", s.name()), slog.Int64("bytes", cr.n),
+			slog.Int("sections", sections), slog.Duration("duration", time.Since(start)))
+	}
+
+	m.recordParse(s, SourceStats{
+		Source:   s.name(),
+		Duration: time.Since(start),
+		Bytes:    cr.n,
+		Sections: parsed,
+		Keys:     keys,
+		Err:      err,
+	})
+	return err
+}
+
+// loadValues adds sections and keys of a map data source as if they were parsed,
+// in alphabetical order. It returns the number of sections and keys loaded.
+func (s *dataSource) loadValues(m *Manager) (sections, keys int, err error) {
+	st := m.stage()
+	for _, name := range slices.Sorted(maps.Keys(s.values)) {
+		sec := st.NewSection(name)
+		if len(name) > 0 {
+			sections++
+		}
+		for _, kname := range slices.Sorted(maps.Keys(s.values[name])) {
+			if len(kname) == 0 {
+				return sections, keys, fmt.Errorf("%w in section %q", ErrEmptyKeyName, name)
+			}
+			if _, err = sec.define(&definition{name: kname, value: s.values[name][kname], layer: s.layer}); err != nil {
+				return sections, keys, err
+			}
+			keys++
+		}
+	}
+	if sections > 0 || keys > 0 {
+		st.contributed.Store(true)
+	}
+	return sections, keys, m.absorb(st)
+}
+
+// recordParse records statistics of a parse of given data source and passes them to Options.OnParse.
+func (m *Manager) recordParse(s *dataSource, ss SourceStats) {
+	m.stats.record(s, ss)
+	if m.options.OnParse != nil {
+		m.options.OnParse(ss)
+	}
+}
+
+// name returns a human readable name of the data source, e.g. file path.
+func (s *dataSource) name() string {
+	if s.path != "" {
+		return s.path
+	}
+	if n, ok := s.readCloser.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// seekStart rewinds the underlying reader to its beginning.
+func (s *dataSource) seekStart() error {
+	var r any = s.reader
+	if s.readCloser != nil {
+		r = s.readCloser
+	}
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return fmt.Errorf("ini: cannot rewind data source of type %T", r)
+	}
+	_, err := seeker.Seek(0, io.SeekStart)
+	return err
+}
+
+// close closes the retained reader if any.
+func (s *dataSource) close() error {
+	if !s.keepOpen || s.readCloser == nil {
+		return nil
+	}
+	return s.readCloser.Close()
+}
+
+// supportedSources lists the kinds of data source accepted by parseDataSource.
+var supportedSources = []string{
+	"string (file path)",
+	"[]byte",
+	"*os.File",
+	"fs.File",
+	"io.ReadCloser",
+	"io.Reader",
+	"DataSource",
+	"func() (io.ReadCloser, error)",
+	"map[string]map[string]string (keys by section name)",
+	"map[string]string (keys of the default section)",
+}
+
+// ErrUnsupportedSource is returned when a data source of unknown type is given.
+type ErrUnsupportedSource struct {
+	// Type is the Go type of the rejected data source.
+	Type reflect.Type
+	// Supported is the list of accepted data source kinds.
+	Supported []string
+}
+
+func (e *ErrUnsupportedSource) Error() string {
+	return fmt.Sprintf("ini: unsupported data source type %v, expected one of: %s",
+		e.Type, strings.Join(e.Supported, ", "))
 }
 
 func parseDataSource(source any) (*dataSource, error) {
 	switch s := source.(type) {
+	case *retainedSource:
+		ds, err := parseDataSource(s.source)
+		if err != nil {
+			return nil, err
+		}
+		ds.keepOpen = s.keepOpen
+		ds.rewind = s.rewind
+		return ds, nil
+	case *layeredSource:
+		ds, err := parseDataSource(s.source)
+		if err != nil {
+			return nil, err
+		}
+		ds.layer = s.layer
+		return ds, nil
 	case string:
 		return &dataSource{path: s}, nil
 	case []byte:
 		return &dataSource{bytes: s}, nil
-	case io.Reader:
-		return &dataSource{reader: s}, nil
+	case *os.File:
+		return &dataSource{readCloser: s}, nil
+	case fs.File:
+		return &dataSource{readCloser: s}, nil
 	case io.ReadCloser:
 		return &dataSource{readCloser: s}, nil
+	case io.Reader:
+		return &dataSource{reader: s}, nil
 	case DataSource:
 		return &dataSource{source: s}, nil
 	case func() (io.ReadCloser, error):
 		return &dataSource{factory: s}, nil
+	case map[string]map[string]string:
+		// Copy the maps, the caller may change them while parsing.
+		values := make(map[string]map[string]string, len(s))
+		for name, keys := range s {
+			values[name] = maps.Clone(keys)
+		}
+		return &dataSource{values: values}, nil
+	case map[string]string:
+		return &dataSource{values: map[string]map[string]string{"": maps.Clone(s)}}, nil
 	default:
-		return nil, fmt.Errorf("error parsing data source: unknown type %q", s)
+		return nil, &ErrUnsupportedSource{
+			Type:      reflect.TypeOf(source),
+			Supported: slices.Clone(supportedSources),
+		}
 	}
 }
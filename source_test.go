@@ -0,0 +1,22 @@
+package ini
+
+import "testing"
+
+func TestMapSourceCopied(t *testing.T) {
+	values := map[string]map[string]string{"s": {"a": "1"}}
+	m, err := LoadSources(Options{}, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values["s"]["a"] = "2"
+	values["t"] = map[string]string{"b": "3"}
+	if err = m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Section("s").Key("a").String(); got != "1" {
+		t.Errorf("s.a = %q, want 1", got)
+	}
+	if m.HasSection("t") {
+		t.Error("section t added after appending was loaded")
+	}
+}
@@ -0,0 +1,152 @@
+package ini
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"weak"
+)
+
+// newSpilledKey creates a new key whose value is stored in a temporary file.
+func (s *Section) newSpilledKey(name, value string) (*Key, error) {
+	// Keep the first definition like NewKey does.
+	if key, err := s.GetKeyLocal(name); err == nil {
+		return key, nil
+	}
+
+	spill, err := s.m.spillValue(value)
+	if err != nil {
+		return nil, err
+	}
+	key := s.NewKey(name, "")
+
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+	key.spill = spill
+	return key, nil
+}
+
+// spillValue writes given value to a new temporary file and returns its name.
+func (m *Manager) spillValue(value string) (string, error) {
+	f, err := os.CreateTemp(m.options.SpillDir, "ini-value-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err = f.WriteString(value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.spilled = append(m.spilled, f.Name())
+	return f.Name(), nil
+}
+
+// dropSpilled removes temporary file of a spilled value which is no longer
+// referenced by any key. The caller must hold the lock.
+func (m *Manager) dropSpilled(name string) {
+	if len(name) == 0 {
+		return
+	}
+	m.spilled = slices.DeleteFunc(m.spilled, func(n string) bool { return n == name })
+	m.spillCache.Delete(name)
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		m.log(slog.LevelWarn, "ini: failed to remove spilled value", slog.String("file", name), errorAttr(err))
+	}
+}
+
+// removeSpilled removes all temporary files created for spilled values.
+func (m *Manager) removeSpilled() error {
+	m.mutex.Lock()
+	files := m.spilled
+	m.spilled = nil
+	m.mutex.Unlock()
+	m.spillCache.Clear()
+	return removeFiles(files)
+}
+
+// removeFiles removes given files, files which do not exist are skipped.
+func removeFiles(files []string) error {
+	var err error
+	for _, name := range files {
+		if e := os.Remove(name); e != nil && !os.IsNotExist(e) && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// rawValue returns raw value of key, spilled value is read from its temporary file.
+// Errors of reading the file are logged and an empty value is returned.
+func (k *Key) rawValue() string {
+	if k.computed != nil {
+		return k.computed.get(k.s, &k.s.m.computeGen)
+	}
+	if len(k.spill) == 0 {
+		return k.value
+	}
+	// Values read are kept until the next garbage collection.
+	if wp, ok := k.s.m.spillCache.Load(k.spill); ok {
+		if v := wp.(weak.Pointer[string]).Value(); v != nil {
+			return *v
+		}
+	}
+
+	data, err := os.ReadFile(k.spill)
+	if err != nil {
+		k.s.m.log(slog.LevelError, "ini: failed to read spilled value", slog.String("section", k.s.name),
+			slog.String("key", k.name), errorAttr(err))
+		return ""
+	}
+	v := string(data)
+	k.s.m.spillCache.Store(k.spill, weak.Make(&v))
+	return v
+}
+
+// Reader returns a reader of the raw value, spilled value is read lazily from its
+// temporary file. The reader must be closed to release the file.
+func (k *Key) Reader() io.ReadCloser {
+	k.markRead()
+	if len(k.spill) == 0 {
+		return io.NopCloser(strings.NewReader(k.value))
+	}
+	return &spillReader{name: k.spill}
+}
+
+// spillReader opens the temporary file on first read.
+type spillReader struct {
+	name string
+	f    *os.File
+	err  error
+}
+
+func (r *spillReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.f == nil {
+		if r.f, r.err = os.Open(r.name); r.err != nil {
+			return 0, r.err
+		}
+	}
+	return r.f.Read(p)
+}
+
+// Close closes the temporary file if it was opened.
+func (r *spillReader) Close() error {
+	if r.err == nil {
+		r.err = os.ErrClosed
+	}
+	if r.f == nil {
+		return nil
+	}
+	return r.f.Close()
+}
@@ -0,0 +1,41 @@
+package ini
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSpilledValues(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	m, err := LoadSources(Options{SpillThreshold: 10, SpillDir: t.TempDir()}, []byte("a = "+long+"\nb = short\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	key := m.Section("").Key("a")
+	for range 2 {
+		if got := key.String(); got != long {
+			t.Errorf("a = %q, want spilled value", got)
+		}
+	}
+	for _, name := range []string{"a", "b"} {
+		r := m.Section("").Key(name).Reader()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if want := m.Section("").Key(name).String(); string(data) != want {
+			t.Errorf("reader of %s = %q, want %q", name, data, want)
+		}
+	}
+
+	key.SetValue("changed")
+	if got := key.String(); got != "changed" {
+		t.Errorf("a = %q after SetValue", got)
+	}
+}
@@ -0,0 +1,92 @@
+package ini
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of counters of a Manager, see Manager.Stats.
+type Stats struct {
+	// Sources are statistics of the last parse of each data source in order of appending,
+	// sources which were never parsed are omitted.
+	Sources []SourceStats
+	// Parses is the number of times data sources were parsed, including failures.
+	Parses int64
+	// ParseErrors is the number of times data sources failed to be parsed.
+	ParseErrors int64
+	// Reloads is the number of times Reload succeeded.
+	Reloads int64
+	// CacheHits and CacheMisses count lookups of contents of referenced files and
+	// decrypted values while transforming values.
+	CacheHits   int64
+	CacheMisses int64
+}
+
+// SourceStats are statistics of a single parse of a data source.
+type SourceStats struct {
+	// Source is the name of the data source, e.g. file path.
+	Source string
+	// Duration is the time spent reading and parsing the data source.
+	Duration time.Duration
+	// Bytes is the number of bytes read from the data source.
+	Bytes int64
+	// Sections and Keys are the number of section headers and key definitions parsed,
+	// including included files.
+	Sections int
+	Keys     int
+	// Err is the error returned by parsing if any.
+	Err error
+}
+
+// statsCounter collects counters of a manager, it is shared with staging managers.
+type statsCounter struct {
+	parses      atomic.Int64
+	parseErrors atomic.Int64
+	reloads     atomic.Int64
+
+	mu      sync.Mutex
+	sources map[*dataSource]SourceStats
+}
+
+// record records statistics of a parse of given data source.
+func (c *statsCounter) record(s *dataSource, ss SourceStats) {
+	c.parses.Add(1)
+	if ss.Err != nil {
+		c.parseErrors.Add(1)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sources == nil {
+		c.sources = make(map[*dataSource]SourceStats)
+	}
+	c.sources[s] = ss
+}
+
+// Stats returns a snapshot of counters of the manager, see Options.OnParse to
+// observe each parse as it happens.
+func (m *Manager) Stats() Stats {
+	st := Stats{
+		Parses:      m.stats.parses.Load(),
+		ParseErrors: m.stats.parseErrors.Load(),
+		Reloads:     m.stats.reloads.Load(),
+	}
+	st.CacheHits, st.CacheMisses = m.files.counts()
+	hits, misses := m.plaintexts.counts()
+	st.CacheHits += hits
+	st.CacheMisses += misses
+
+	m.mutex.RLock()
+	sources := m.sources
+	m.mutex.RUnlock()
+
+	m.stats.mu.Lock()
+	defer m.stats.mu.Unlock()
+	for _, s := range sources {
+		if ss, ok := m.stats.sources[s]; ok {
+			st.Sources = append(st.Sources, ss)
+		}
+	}
+	return st
+}
@@ -0,0 +1,153 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	reflectDuration = reflect.TypeOf(time.Duration(0))
+	reflectTime     = reflect.TypeOf(time.Time{})
+)
+
+// MapTo maps keys of section to given struct pointer, field names are
+// taken from the "ini" tag or the field name itself, "-" skips the field.
+// List fields are divided by the "delim" tag, which defaults to ",".
+// Fields of struct type or pointer to struct type other than time.Time are
+// skipped, Manager.MapTo maps them from sections.
+func (s *Section) MapTo(v any) error {
+	return s.mapTo(v, "")
+}
+
+// MapPrefixTo maps keys sharing given prefix to given struct pointer after
+// stripping the prefix, e.g. smtp_host and smtp_port are mapped to fields
+// named host and port with prefix "smtp_".
+func (s *Section) MapPrefixTo(prefix string, v any) error {
+	return s.mapTo(v, prefix)
+}
+
+func (s *Section) mapTo(v any, prefix string) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("ini: cannot map to non-pointer struct")
+	}
+	val = val.Elem()
+	typ := val.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// Struct fields are mapped from sections by Manager.MapTo.
+		if !field.IsExported() || isSectionType(field.Type) {
+			continue
+		}
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		key, err := s.GetKey(prefix + name)
+		if err != nil {
+			continue
+		}
+		delim := field.Tag.Get("delim")
+		if len(delim) == 0 {
+			delim = ","
+		}
+		if err = setField(val.Field(i), key, delim); err != nil {
+			return fmt.Errorf("ini: error mapping field %q: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// fieldName returns the key name of given struct field, false if skipped.
+func fieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("ini")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); len(name) > 0 {
+		return name, true
+	}
+	return field.Name, true
+}
+
+// setField sets value of key to given field with respect of its type,
+// elements of slice fields are parsed from the value divided by delim.
+func setField(field reflect.Value, key *Key, delim string) error {
+	if field.Kind() != reflect.Slice {
+		return setValue(field, key.String(), key.timeLocation())
+	}
+
+	strs := key.Strings(delim)
+	slice := reflect.MakeSlice(field.Type(), len(strs), len(strs))
+	for i, str := range strs {
+		if err := setValue(slice.Index(i), str, key.timeLocation()); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
+// setValue parses given value into given field with respect of its type,
+// loc is used for time values lacking time zone information.
+func setValue(field reflect.Value, val string, loc *time.Location) error {
+	switch field.Type() {
+	case reflectDuration:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case reflectTime:
+		t, err := parseTimeIn(time.RFC3339, val, loc)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := parseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 0, 64)
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(n) {
+			return fmt.Errorf("value %q overflows %v", val, field.Type())
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 0, 64)
+		if err != nil {
+			return err
+		}
+		if field.OverflowUint(n) {
+			return fmt.Errorf("value %q overflows %v", val, field.Type())
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %v", field.Type())
+	}
+	return nil
+}
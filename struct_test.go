@@ -0,0 +1,44 @@
+package ini
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMapToOverflow(t *testing.T) {
+	m, err := LoadSources(Options{}, []byte("small = 300\nunsigned = 70000\nlist = 1,2,300\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []any{
+		&struct {
+			Small int8 `ini:"small"`
+		}{},
+		&struct {
+			Unsigned uint16 `ini:"unsigned"`
+		}{},
+		&struct {
+			List []int8 `ini:"list"`
+		}{},
+	} {
+		if err = m.Section("").MapTo(v); err == nil {
+			t.Errorf("mapping to %T succeeded", v)
+		}
+	}
+}
+
+func TestMapToSlice(t *testing.T) {
+	m, err := LoadSources(Options{}, []byte("base = 1\nlist = %(base)s, 2, 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		List []int `ini:"list"`
+	}
+	if err = m.Section("").MapTo(&v); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(v.List, []int{1, 2, 3}) {
+		t.Errorf("list = %v, want [1 2 3]", v.List)
+	}
+}
@@ -0,0 +1,130 @@
+package ini
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tomlBare matches keys which can be written as bare TOML keys.
+var tomlBare = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ToTOML returns all values encoded as TOML. Keys of the default section are placed
+// at the top level, sections are written as tables and child sections as nested tables,
+// e.g. [server.http]. Tables take precedence over keys with the same dotted name,
+// e.g. key http of section server along with section server.http, which are skipped.
+// Comments of sections and keys are preserved, values of sensitive keys are redacted,
+// see Key.SetSensitive.
+func (m *Manager) ToTOML() ([]byte, error) {
+	tables := m.tomlTables()
+	var buf bytes.Buffer
+	for sec := range m.All() {
+		var table string
+		if len(sec.name) > 0 {
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			writeTOMLComment(&buf, sec.Comment)
+			table = m.tomlTable(sec.Name())
+			buf.WriteString("[" + table + "]\n")
+		} else {
+			writeTOMLComment(&buf, sec.Comment)
+		}
+		for name, key := range sec.All() {
+			if tables[tomlPath(table, tomlKey(name))] {
+				continue
+			}
+			writeTOMLComment(&buf, key.Comment)
+			buf.WriteString(tomlKey(name) + " = " + tomlString(key.redacted(key.expand())) + "\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// tomlTables returns the dotted TOML names of all tables, including tables defined
+// implicitly by child sections, e.g. a.b by section a.b.c.
+func (m *Manager) tomlTables() map[string]bool {
+	tables := make(map[string]bool)
+	delim := m.options.ChildSectionDelimiter
+	for sec := range m.All() {
+		if len(sec.name) == 0 {
+			continue
+		}
+		parts := strings.Split(sec.Name(), delim)
+		for i := range parts {
+			tables[m.tomlTable(strings.Join(parts[:i+1], delim))] = true
+		}
+	}
+	return tables
+}
+
+// tomlPath returns the dotted TOML name of given key of given table,
+// which is empty for the top level.
+func tomlPath(table, key string) string {
+	if len(table) == 0 {
+		return key
+	}
+	return table + "." + key
+}
+
+// tomlTable returns the dotted TOML table name of given section name.
+func (m *Manager) tomlTable(name string) string {
+	parts := strings.Split(name, m.options.ChildSectionDelimiter)
+	for i, part := range parts {
+		parts[i] = tomlKey(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// writeTOMLComment writes every line of given comment with '#' as comment symbol.
+func writeTOMLComment(buf *bytes.Buffer, comment string) {
+	if len(comment) == 0 {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimLeft(strings.TrimSpace(line), "#;")
+		buf.WriteString("#" + strings.TrimRight(line, " \t") + "\n")
+	}
+}
+
+// tomlKey returns given name as a bare key if possible, otherwise as a quoted key.
+func tomlKey(name string) string {
+	if tomlBare.MatchString(name) {
+		return name
+	}
+	return tomlString(name)
+}
+
+// tomlString returns s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
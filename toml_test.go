@@ -0,0 +1,19 @@
+package ini
+
+import "testing"
+
+func TestToTOMLTableKeys(t *testing.T) {
+	data := "server = default\n[server]\nhttp = key\nport = 80\n[server.http]\nhost = localhost\n[a]\nb = key\n[a.b.c]\nd = 1\n"
+	m, err := LoadSources(Options{}, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.ToTOML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[server]\nport = \"80\"\n\n[server.http]\nhost = \"localhost\"\n\n[a]\n\n[a.b.c]\nd = \"1\"\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package ini
+
+import "io"
+
+// TracerProvider starts spans around loading data sources. It is kept free of
+// any tracing dependency, so a thin adapter is needed for e.g. OpenTelemetry.
+type TracerProvider interface {
+	// StartSpan starts a span with given name, e.g. "ini.Append", "ini.Parse", "ini.Reload" and "ini.Save".
+	StartSpan(name string) Span
+}
+
+// Span is a single traced operation started by TracerProvider.
+type Span interface {
+	// SetAttribute records an attribute of the operation, e.g. source, file, bytes and sections.
+	SetAttribute(key string, value any)
+	// End finishes the span, err is the error returned by the operation if any.
+	End(err error)
+}
+
+// noopSpan is used when no TracerProvider is configured.
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) End(error)                {}
+
+// startSpan starts a span using the configured TracerProvider.
+func (m *Manager) startSpan(name string) Span {
+	if m.options.TracerProvider == nil {
+		return noopSpan{}
+	}
+	return m.options.TracerProvider.StartSpan(name)
+}
+
+// countingReader counts bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -1,13 +1,22 @@
 package ini
 
 import (
+	"errors"
 	"os"
 	"regexp"
 	"strings"
 )
 
-// Maximum allowed depth when recursively substituing variable names.
-const depthValues = 99
+const (
+	// Maximum allowed depth when recursively substituing variable names.
+	depthValues = 99
+	// Maximum allowed length of a value after interpolation.
+	defaultMaxExpandedLength = 1 << 20
+)
+
+// ErrExpansionLimit is returned when interpolating a value exceeds
+// Options.MaxExpandedLength or Options.MaxSubstitutions.
+var ErrExpansionLimit = errors.New("ini: value interpolation exceeds limit")
 
 var (
 	// Variable regexp pattern: %(variable)s
@@ -23,24 +32,63 @@ var (
 type ValueTransformer func(m *Manager, s *Section, k *Key) string
 
 // transformValue takes a key and transforms to its final string.
-func transformValue(k *Key) string {
+func transformValue(k *Key) (string, error) {
 	val := transformCustom(k)
-	val = transformReference(k, val)
-	val = transformEnvironment(val)
-	return val
+	if plain, ok, err := k.decrypt(val); ok {
+		return plain, err
+	}
+	// Fast path for plain values which have nothing to interpolate.
+	if !strings.ContainsAny(val, "%$") && !k.s.m.options.ResolveFileReferences {
+		return val, nil
+	}
+
+	q := &expansionQuota{
+		maxLength: k.s.m.options.MaxExpandedLength,
+		remaining: k.s.m.options.MaxSubstitutions,
+	}
+	val, err := transformReference(k, val, q)
+	if err != nil {
+		return "", err
+	}
+	if val, err = transformEnvironment(val, &k.s.m.options, q); err != nil {
+		return "", err
+	}
+	if k.s.m.options.ResolveFileReferences {
+		return k.s.m.files.resolve(val, k.s.m.options.MaxFileReferenceSize)
+	}
+	return val, nil
+}
+
+// expansionQuota limits the growth of a value while interpolating.
+type expansionQuota struct {
+	maxLength int
+	remaining int
+}
+
+// replace substitutes all occurrences of old in val by new within the quota. A reference
+// counts as one substitution however often it occurs, growth is bounded by the length.
+func (q *expansionQuota) replace(val, old, new string) (string, error) {
+	n := strings.Count(val, old)
+	if q.maxLength > 0 && len(val)+n*(len(new)-len(old)) > q.maxLength {
+		return "", ErrExpansionLimit
+	}
+	if q.remaining--; q.remaining < 0 {
+		return "", ErrExpansionLimit
+	}
+	return strings.Replace(val, old, new, -1), nil
 }
 
 func transformCustom(k *Key) string {
 	if k.s.m.options.Transformer != nil {
 		return k.s.m.options.Transformer(k.s.m, k.s, k)
 	}
-	return k.value
+	return k.rawValue()
 }
 
-func transformReference(k *Key, val string) string {
+func transformReference(k *Key, val string, q *expansionQuota) (string, error) {
 	// Fail-fast if no indicate char found for recursive value
 	if !strings.Contains(val, "%") {
-		return val
+		return val, nil
 	}
 
 	for range depthValues {
@@ -53,28 +101,36 @@ func transformReference(k *Key, val string) string {
 		noption := vr[2 : len(vr)-2]
 
 		// Search in the same section.
-		// If not found or found the key itself, then search again in default section.
+		// If not found or found the key itself, then search again in default section,
+		// and finally in load-time defines.
 		nk, err := k.s.GetKey(noption)
 		if err != nil || k == nk {
 			nk, _ = k.s.m.Section("").GetKey(noption)
-			if nk == nil {
-				// Stop when no results found in the default section,
-				// and returns the value as-is.
-				break
-			}
+		}
+		var value string
+		if nk != nil {
+			value = nk.rawValue()
+		} else if def, ok := k.s.m.options.Defines[noption]; ok {
+			value = def
+		} else {
+			// Stop when no results found in the default section and defines,
+			// and returns the value as-is.
+			break
 		}
 
 		// Substitute by new value and take off leading '%(' and trailing ')s'.
-		val = strings.Replace(val, vr, nk.value, -1)
+		if val, err = q.replace(val, vr, value); err != nil {
+			return "", err
+		}
 	}
 
-	return val
+	return val, nil
 }
 
-func transformEnvironment(val string) string {
+func transformEnvironment(val string, opts *Options, q *expansionQuota) (string, error) {
 	// Fail-fast if no indicate char found for recursive value
 	if !strings.Contains(val, "$") {
-		return val
+		return val, nil
 	}
 
 	for range depthValues {
@@ -102,18 +158,48 @@ func transformEnvironment(val string) string {
 			def = trimQuote(strings.TrimSpace(parts[1]))
 		}
 
-		// Get the value from environment.
+		// Get the value from defines, then from environment.
 		// If no value found, then use default value.
-		value, ok := os.LookupEnv(key)
+		value, ok := opts.Defines[key]
+		if !ok && envAllowed(opts, key) {
+			value, ok = os.LookupEnv(key)
+		}
 		if !ok || (value == "" && force) {
 			value = def
 		}
 
 		// Substitute by new value and take off leading '${' and trailing '}'.
-		val = strings.Replace(val, vr, value, -1)
+		var err error
+		if val, err = q.replace(val, vr, value); err != nil {
+			return "", err
+		}
 	}
 
-	return val
+	return val, nil
+}
+
+// envAllowed reports whether environment variable of given name may be resolved
+// with respect of Options.EnvAllow and Options.EnvDeny.
+func envAllowed(opts *Options, name string) bool {
+	if matchEnvName(opts.EnvDeny, name) {
+		return false
+	}
+	return len(opts.EnvAllow) == 0 || matchEnvName(opts.EnvAllow, name)
+}
+
+// matchEnvName reports whether name equals to any of given names, or starts with
+// any of given prefixes ending with "*".
+func matchEnvName(names []string, name string) bool {
+	for _, n := range names {
+		if prefix, ok := strings.CutSuffix(n, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if n == name {
+			return true
+		}
+	}
+	return false
 }
 
 func trimQuote(s string) string {
@@ -0,0 +1,94 @@
+package ini
+
+// View is an immutable snapshot of sections and interpolated values of keys,
+// it is safe for concurrent use without taking any lock.
+type View struct {
+	m        *Manager
+	names    []string
+	sections map[string]*viewSection
+}
+
+type viewSection struct {
+	name   string
+	keys   []string
+	values map[string]string
+}
+
+// View returns a snapshot of current data, it is taken when View is first called and
+// replaced atomically by Append and Reload. Changes made by SetValue, NewKey and the
+// like are not visible until the next Append or Reload.
+func (m *Manager) View() *View {
+	if v := m.view.Load(); v != nil {
+		return v
+	}
+	v := m.snapshot()
+	if !m.view.CompareAndSwap(nil, v) {
+		return m.view.Load()
+	}
+	return v
+}
+
+// refreshView replaces the snapshot returned by View if any was taken.
+func (m *Manager) refreshView() {
+	if m.view.Load() != nil {
+		m.view.Store(m.snapshot())
+	}
+}
+
+// snapshot returns a new View of current data.
+func (m *Manager) snapshot() *View {
+	v := &View{m: m, sections: make(map[string]*viewSection)}
+	for sec := range m.All() {
+		vs := &viewSection{name: sec.Name(), values: make(map[string]string)}
+		for name, key := range sec.All() {
+			vs.keys = append(vs.keys, name)
+			vs.values[key.name] = key.expand()
+		}
+		v.names = append(v.names, vs.name)
+		v.sections[sec.name] = vs
+	}
+	return v
+}
+
+// Sections returns names of sections in order of definition.
+func (v *View) Sections() []string {
+	return append([]string(nil), v.names...)
+}
+
+// HasSection returns true if the snapshot contains a section with given name.
+func (v *View) HasSection(name string) bool {
+	_, ok := v.sections[v.m.foldSection(name)]
+	return ok
+}
+
+// Keys returns names of keys of given section in order of definition.
+func (v *View) Keys(section string) []string {
+	if vs, ok := v.sections[v.m.foldSection(section)]; ok {
+		return append([]string(nil), vs.keys...)
+	}
+	return nil
+}
+
+// Value returns interpolated value of key in given section, keys of parent sections
+// are looked up when not found unless Options.DisableParentInheritance is set.
+func (v *View) Value(section, key string) (string, bool) {
+	section, key = v.m.foldSection(section), v.m.foldKey(key)
+	for {
+		if vs, ok := v.sections[section]; ok {
+			if val, ok := vs.values[key]; ok {
+				return val, true
+			}
+		}
+		parent, ok := v.m.parentName(section)
+		if !ok || v.m.options.DisableParentInheritance {
+			return "", false
+		}
+		section = parent
+	}
+}
+
+// String returns interpolated value of key in given section, or empty string if not found.
+func (v *View) String(section, key string) string {
+	val, _ := v.Value(section, key)
+	return val
+}
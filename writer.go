@@ -0,0 +1,523 @@
+package ini
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// WriteOptions contains options used when writing data to an io.Writer.
+type WriteOptions struct {
+	// SortSections sorts sections before writing, e.g. strings.Compare for
+	// alphabetical order. The default section is always written first.
+	// Sections are written in order of definition when nil.
+	SortSections func(a, b string) int
+	// SortKeys sorts keys within each section before writing, e.g. strings.Compare
+	// for alphabetical order. Keys are written in order of definition when nil.
+	SortKeys func(a, b string) int
+	// Obfuscator replaces every non-empty value before writing, see HMACObfuscator.
+	Obfuscator func(value string) string
+	// QuoteStyle is the preferred style to quote values, QuoteAuto is used
+	// for values which cannot be parsed back in the preferred style.
+	QuoteStyle QuoteStyle
+	// Fidelity is the level of detail to write, comments are skipped with
+	// FidelityValues.
+	Fidelity Fidelity
+	// ControlChars decides how control characters in values are written,
+	// by default they are kept as-is.
+	ControlChars ControlCharPolicy
+	// DefaultKeyGroups groups keys of the default section by name prefix, each group
+	// is written under a pseudo-header comment like "# --- name ---". A key belongs
+	// to the first matching group, keys matching no group are written first.
+	DefaultKeyGroups []KeyGroup
+	// LineEnding is the line break to write, i.e. "\n" or "\r\n". By default,
+	// the line ending detected in the first data source is used, or "\n" if none.
+	LineEnding string
+	// CommentSymbol replaces the leading symbol of every comment line, i.e. "#" or ";".
+	// By default, comments are written with the symbols they were parsed with.
+	CommentSymbol string
+	// InlineComments indicates whether comments parsed at the end of key lines are written
+	// there, by default they are written above the key along with other comments.
+	InlineComments bool
+	// CommentWidth is the width at which comment lines are wrapped at spaces,
+	// comment lines are not wrapped when it is zero.
+	CommentWidth int
+	// RevealSensitive indicates whether values of sensitive keys are written as-is
+	// instead of RedactedValue, see Key.SetSensitive. It is always set by SaveTo and SaveWith,
+	// which write the values encrypted by Options.Encryptor if set.
+	RevealSensitive bool
+
+	encrypt bool // encrypt values of sensitive keys by Options.Encryptor
+}
+
+// KeyGroup is a group of keys sharing a name prefix, empty prefix matches all keys.
+type KeyGroup struct {
+	Name   string
+	Prefix string
+}
+
+// header returns the pseudo-header comment of the group.
+func (g KeyGroup) header() string {
+	return "# --- " + g.Name + " ---"
+}
+
+// WriteTo writes data in INI format to given io.Writer, values of sensitive keys
+// are redacted, see Key.SetSensitive.
+func (m *Manager) WriteTo(w io.Writer) (int64, error) {
+	return m.WriteWith(w, WriteOptions{})
+}
+
+// WriteWith writes data in INI format to given io.Writer with given options.
+func (m *Manager) WriteWith(w io.Writer, opts WriteOptions) (int64, error) {
+	m.materialize()
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	names := slices.Clone(m.sectionList)
+	if opts.SortSections != nil {
+		slices.SortStableFunc(names, func(a, b string) int {
+			// Keep the default section on top since it has no header.
+			switch {
+			case a == b:
+				return 0
+			case a == "":
+				return -1
+			case b == "":
+				return 1
+			}
+			return opts.SortSections(a, b)
+		})
+	}
+
+	w, bom := m.encode(w)
+	buf := bufio.NewWriter(w)
+	bw := &countingWriter{w: buf, crlf: m.lineEnding(opts.LineEnding) == "\r\n"}
+	bw.writeBOM(bom)
+	for _, name := range names {
+		sec := m.sections[name]
+		if bw.hasContent() {
+			bw.WriteString("\n")
+		}
+		if opts.Fidelity == FidelityFull {
+			writeComment(bw, sec.Comment, opts)
+		}
+		if len(name) > 0 {
+			bw.WriteString("[" + m.sectionHeader(sec.Name()) + "]\n")
+		}
+
+		keys := slices.Clone(sec.keyList)
+		if opts.SortKeys != nil {
+			slices.SortStableFunc(keys, opts.SortKeys)
+		}
+		if len(name) == 0 && len(opts.DefaultKeyGroups) > 0 {
+			writeGroupedKeys(bw, sec, keys, opts)
+			continue
+		}
+		for _, kname := range keys {
+			writeKey(bw, sec.keys[kname], opts)
+		}
+	}
+
+	if bw.err != nil {
+		return bw.n, bw.err
+	}
+	return bw.n, buf.Flush()
+}
+
+// WriteObfuscated writes data in INI format to given io.Writer with values
+// replaced by stable tokens derived from given salt, equal values produce
+// equal tokens so the structure can be shared without revealing real data.
+func (m *Manager) WriteObfuscated(w io.Writer, salt []byte) (int64, error) {
+	return m.WriteWith(w, WriteOptions{Obfuscator: HMACObfuscator(salt)})
+}
+
+// HMACObfuscator returns an obfuscator replacing values with HMAC-SHA256
+// derived tokens keyed by given salt.
+func HMACObfuscator(salt []byte) func(value string) string {
+	return func(value string) string {
+		mac := hmac.New(sha256.New, salt)
+		mac.Write([]byte(value))
+		return "obf-" + hex.EncodeToString(mac.Sum(nil)[:8])
+	}
+}
+
+// SaveTo writes data in INI format to given file.
+func (m *Manager) SaveTo(filename string) error {
+	return m.SaveWith(filename, WriteOptions{})
+}
+
+// SaveWith writes data in INI format to given file with given options.
+// It returns ErrConditional when Options.Condition is set.
+func (m *Manager) SaveWith(filename string, opts WriteOptions) (err error) {
+	span := m.startSpan("ini.Save")
+	span.SetAttribute("file", filename)
+	defer func() { span.End(err) }()
+
+	if m.options.Condition != nil {
+		return ErrConditional
+	}
+
+	opts.RevealSensitive = true
+	opts.encrypt = true
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	n, err := m.WriteWith(f, opts)
+	span.SetAttribute("bytes", n)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	m.markClean()
+	return nil
+}
+
+// writeGroupedKeys writes keys of the default section grouped by opts.DefaultKeyGroups.
+func writeGroupedKeys(w *countingWriter, sec *Section, keys []string, opts WriteOptions) {
+	groups := make([][]string, len(opts.DefaultKeyGroups)+1)
+	for _, kname := range keys {
+		i := slices.IndexFunc(opts.DefaultKeyGroups, func(g KeyGroup) bool {
+			return strings.HasPrefix(kname, g.Prefix)
+		})
+		// Ungrouped keys go to the last slot but are written first.
+		if i < 0 {
+			i = len(opts.DefaultKeyGroups)
+		}
+		groups[i] = append(groups[i], kname)
+	}
+
+	headers := make([]string, len(opts.DefaultKeyGroups))
+	for i, g := range opts.DefaultKeyGroups {
+		headers[i] = g.header()
+	}
+
+	writeKeys := func(names []string) {
+		for _, kname := range names {
+			k := *sec.keys[kname]
+			// Headers parsed back as key comments must not be written twice.
+			k.Comment = stripHeaders(k.Comment, headers)
+			writeKey(w, &k, opts)
+		}
+	}
+
+	writeKeys(groups[len(opts.DefaultKeyGroups)])
+	for i, g := range opts.DefaultKeyGroups {
+		if len(groups[i]) == 0 {
+			continue
+		}
+		if w.hasContent() {
+			w.WriteString("\n")
+		}
+		w.WriteString(g.header() + "\n")
+		writeKeys(groups[i])
+	}
+}
+
+// stripHeaders removes lines of comment equal to any of given headers.
+func stripHeaders(comment string, headers []string) string {
+	if len(comment) == 0 {
+		return comment
+	}
+	lines := strings.Split(comment, "\n")
+	lines = slices.DeleteFunc(lines, func(line string) bool {
+		return slices.Contains(headers, strings.TrimSpace(line))
+	})
+	return strings.Join(lines, "\n")
+}
+
+func writeComment(w *countingWriter, comment string, opts WriteOptions) {
+	if comment = formatComment(comment, opts); len(comment) > 0 {
+		w.WriteString(comment + "\n")
+	}
+}
+
+// formatComment normalizes given comment and applies comment options of opts.
+func formatComment(comment string, opts WriteOptions) string {
+	comment = normalizeComment(comment)
+	if len(comment) == 0 || len(opts.CommentSymbol) == 0 && opts.CommentWidth <= 0 {
+		return comment
+	}
+
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		if len(opts.CommentSymbol) > 0 {
+			symbols := len(line) - len(strings.TrimLeft(line, "#;"))
+			line = strings.Repeat(opts.CommentSymbol, symbols) + line[symbols:]
+		}
+		lines = append(lines, wrapComment(line, opts.CommentWidth)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapComment splits given comment line at spaces into lines no longer than width
+// when possible, every line starts with the comment symbols of given line.
+func wrapComment(line string, width int) []string {
+	if width <= 0 || len(line) <= width {
+		return []string{line}
+	}
+	rest := strings.TrimLeft(line, "#;")
+	prefix := line[:len(line)-len(rest)] + " "
+	words := strings.Fields(rest)
+	if len(words) < 2 {
+		return []string{line}
+	}
+
+	var lines []string
+	cur := prefix + words[0]
+	for _, word := range words[1:] {
+		if len(cur)+1+len(word) > width {
+			lines = append(lines, cur)
+			cur = prefix + word
+			continue
+		}
+		cur += " " + word
+	}
+	return append(lines, cur)
+}
+
+func writeKey(w *countingWriter, k *Key, opts WriteOptions) {
+	// Inline comment is only kept when it is still the last line of the comment.
+	comment, inline := k.Comment, ""
+	if opts.InlineComments && len(k.inlineComment) > 0 && strings.HasSuffix(comment, k.inlineComment) {
+		comment = strings.TrimSuffix(comment, k.inlineComment)
+		inline = " " + formatComment(k.inlineComment, WriteOptions{CommentSymbol: opts.CommentSymbol})
+	}
+	if opts.Fidelity == FidelityFull {
+		writeComment(w, comment, opts)
+	} else {
+		inline = ""
+	}
+
+	name := k.Name()
+	if k.isAutoIncrement {
+		name = "-"
+	} else {
+		name = quoteKeyName(name)
+	}
+	if k.isArray {
+		name += "[]"
+	}
+
+	if k.isBooleanType {
+		w.WriteString(name + inline + "\n")
+		return
+	}
+
+	// protect redacts values of sensitive keys, or encrypts them when saving.
+	protect := func(val string) string {
+		if !opts.RevealSensitive {
+			return k.redact(val)
+		}
+		enc := k.s.m.options.Encryptor
+		if !opts.encrypt || enc == nil || len(val) == 0 || !k.isSensitive() {
+			return val
+		}
+		if _, ok := ciphertext(val); ok {
+			return val
+		}
+		val, err := encryptValue(enc, val)
+		if err != nil && w.err == nil {
+			w.err = fmt.Errorf("ini: key %q: %w", k.name, err)
+		}
+		return val
+	}
+	// prepare protects and obfuscates a value, and applies the control character policy.
+	prepare := func(val string) string {
+		val = protect(val)
+		if opts.Obfuscator != nil && len(val) > 0 {
+			val = opts.Obfuscator(val)
+		}
+		val, err := applyControlCharPolicy(val, opts.ControlChars)
+		if err != nil && w.err == nil {
+			w.err = fmt.Errorf("ini: key %q: %w", k.name, err)
+		}
+		return val
+	}
+	val := prepare(k.rawValue())
+	if w.err != nil {
+		return
+	}
+	w.WriteString(name + " = " + quoteValueStyle(val, opts.QuoteStyle, &k.s.m.options) + inline + "\n")
+	for _, val := range k.shadows {
+		if val = prepare(val); w.err != nil {
+			return
+		}
+		w.WriteString(name + " = " + quoteValueStyle(val, opts.QuoteStyle, &k.s.m.options) + "\n")
+	}
+	for _, val := range k.nestedValues {
+		if val = prepare(val); w.err != nil {
+			return
+		}
+		w.WriteString("  " + val + "\n")
+	}
+}
+
+// quoteKeyName surrounds key name with quotes when it cannot be parsed back as-is,
+// e.g. names starting with "[" would be parsed as section headers and "-" as an
+// auto-increment key.
+func quoteKeyName(name string) string {
+	if !strings.ContainsAny(name, "=:#;`\"") && strings.TrimSpace(name) == name &&
+		!strings.HasPrefix(name, "[") && name != "-" {
+		return name
+	}
+	if strings.Contains(name, "`") {
+		return `"""` + name + `"""`
+	}
+	return "`" + name + "`"
+}
+
+// quoteValue surrounds value with quotes when it cannot be parsed back as-is
+// with given parsing options.
+func quoteValue(val string, opts *Options) string {
+	switch {
+	case strings.ContainsAny(val, "\n`"):
+		return `"""` + val + `"""`
+	case needsQuote(val, opts):
+		return "`" + val + "`"
+	}
+	return val
+}
+
+// needsQuote returns true if value would be changed when parsed back as-is.
+func needsQuote(val string, opts *Options) bool {
+	switch {
+	case strings.TrimSpace(val) != val, strings.HasPrefix(val, `"""`):
+		return true
+	case !opts.IgnoreInlineComment && strings.ContainsAny(val, "#;"):
+		return true
+	case !opts.IgnoreContinuation && strings.HasSuffix(val, `\`):
+		return true
+	case !opts.PreserveSurroundedQuote && strings.ContainsAny(val, `"'`):
+		return true
+	case opts.UnescapeValueDoubleQuotes && strings.HasPrefix(val, `"`):
+		return true
+	case opts.UnescapeValueCommentSymbols && strings.Contains(val, `\`):
+		return true
+	}
+	return false
+}
+
+// quoteValueStyle surrounds value with quotes of given style if it can be
+// parsed back that way, otherwise it falls back to quoteValue.
+func quoteValueStyle(val string, style QuoteStyle, opts *Options) string {
+	switch style {
+	case QuoteDouble:
+		if !strings.ContainsAny(val, "\"\n#;") && !strings.HasSuffix(val, "\\") {
+			return `"` + val + `"`
+		}
+	case QuoteBacktick:
+		if !strings.Contains(val, "`") {
+			return "`" + val + "`"
+		}
+	case QuoteTriple:
+		if !strings.Contains(val, `"""`) && !strings.HasSuffix(val, `"`) {
+			return `"""` + val + `"""`
+		}
+	}
+	return quoteValue(val, opts)
+}
+
+// crlfReplacer converts line breaks to CRLF, existing CRLF line breaks are kept.
+var crlfReplacer = strings.NewReplacer("\r\n", "\r\n", "\n", "\r\n")
+
+// lineEnding returns given line ending, or the line ending detected in data sources if empty.
+// It must be called while holding the lock.
+func (m *Manager) lineEnding(ending string) string {
+	if len(ending) > 0 {
+		return ending
+	}
+	if len(m.newline) > 0 {
+		return m.newline
+	}
+	return "\n"
+}
+
+// detectEncoding records the encoding detected by BOM if none was detected.
+func (m *Manager) detectEncoding(enc Encoding) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.encoding == nil {
+		m.encoding = enc
+	}
+}
+
+// encode returns a writer encoding data by Options.Encoding, or by the encoding
+// detected in data sources along with true to write a BOM.
+// It must be called while holding the lock.
+func (m *Manager) encode(w io.Writer) (io.Writer, bool) {
+	if m.options.Encoding != nil {
+		return m.options.Encoding.Encode(w), false
+	}
+	if m.encoding != nil {
+		return m.encoding.Encode(w), true
+	}
+	return w, false
+}
+
+// detectLineEnding records the line ending of given line if none was detected,
+// so the first data source decides the line ending to write.
+func (m *Manager) detectLineEnding(line []byte) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if len(m.newline) > 0 {
+		return
+	}
+	m.newline = "\n"
+	if bytes.HasSuffix(line, []byte("\r\n")) {
+		m.newline = "\r\n"
+	}
+}
+
+// countingWriter counts written bytes and remembers the first error,
+// line breaks are converted to CRLF when crlf is set.
+type countingWriter struct {
+	w    io.Writer
+	n    int64
+	err  error
+	crlf bool
+	bom  int64 // length of the written BOM
+}
+
+// writeBOM writes a BOM if desired, it is not taken as content.
+func (c *countingWriter) writeBOM(bom bool) {
+	if bom {
+		c.WriteString("\ufeff")
+		c.bom = c.n
+	}
+}
+
+// hasContent returns true if anything but a BOM was written.
+func (c *countingWriter) hasContent() bool {
+	return c.n > c.bom
+}
+
+func (c *countingWriter) WriteString(s string) {
+	if c.err != nil {
+		return
+	}
+	if c.crlf {
+		s = crlfReplacer.Replace(s)
+	}
+	n, err := io.WriteString(c.w, s)
+	c.n += int64(n)
+	c.err = err
+}
+
+// quoteSectionName surrounds section name with double quotes when it cannot be parsed back as-is.
+func quoteSectionName(name string) string {
+	if !strings.ContainsAny(name, "]\"\n") && strings.TrimSpace(name) == name {
+		return name
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+}
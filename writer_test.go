@@ -0,0 +1,124 @@
+package ini
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWriteFidelity(t *testing.T) {
+	data := "; comment\n[s]\na = 1\na = 2\n# key comment\nb =\n  nested\n"
+	opts := Options{DuplicateKeys: DuplicateShadow, AllowNestedValues: true}
+	m, err := LoadSources(opts, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		fidelity Fidelity
+		want     string
+	}{
+		{FidelityFull, "; comment\n[s]\na = 1\na = 2\n# key comment\nb = \n  nested\n"},
+		{FidelityValues, "[s]\na = 1\na = 2\nb = \n  nested\n"},
+	} {
+		var buf bytes.Buffer
+		if _, err = m.WriteWith(&buf, WriteOptions{Fidelity: tt.fidelity}); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%v:\ngot:\n%s\nwant:\n%s", tt.fidelity, buf.String(), tt.want)
+		}
+
+		// Values written with any fidelity are parsed back the same.
+		back, err := LoadSources(opts, buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := back.Section("s").Key("a").ValueWithShadows(); len(got) != 2 {
+			t.Errorf("%v: values of a = %q, want 2 values", tt.fidelity, got)
+		}
+	}
+}
+
+func TestWriteQuoteStyle(t *testing.T) {
+	m := New(Options{})
+	sec := m.NewSection("s")
+	sec.NewKey("plain", "value")
+	sec.NewKey("hash", "a # b")
+	for _, tt := range []struct {
+		style QuoteStyle
+		want  string
+	}{
+		{QuoteAuto, "[s]\nplain = value\nhash = `a # b`\n"},
+		{QuoteDouble, "[s]\nplain = \"value\"\nhash = `a # b`\n"},
+		{QuoteBacktick, "[s]\nplain = `value`\nhash = `a # b`\n"},
+		{QuoteTriple, "[s]\nplain = \"\"\"value\"\"\"\nhash = \"\"\"a # b\"\"\"\n"},
+	} {
+		var buf bytes.Buffer
+		if _, err := m.WriteWith(&buf, WriteOptions{QuoteStyle: tt.style}); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%v:\ngot:\n%s\nwant:\n%s", tt.style, buf.String(), tt.want)
+		}
+
+		back, err := LoadSources(Options{}, buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range sec.Keys() {
+			if got := back.Section("s").Key(key.Name()).String(); got != key.String() {
+				t.Errorf("%v: %s = %q, want %q", tt.style, key.Name(), got, key.String())
+			}
+		}
+	}
+}
+
+func TestSaveConditional(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.ini")
+	data := []byte("[db @production]\nhost = prod\n[db @staging]\nhost = staging\n")
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadSources(Options{Condition: ConditionContext(nil, "production")}, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Section("db").Key("host").String(); got != "prod" {
+		t.Errorf("db.host = %q, want prod", got)
+	}
+	if err = m.SaveTo(filename); !errors.Is(err, ErrConditional) {
+		t.Errorf("SaveTo error = %v, want ErrConditional", err)
+	}
+	if got, _ := os.ReadFile(filename); string(got) != string(data) {
+		t.Errorf("file was overwritten:\n%s", got)
+	}
+}
+
+func TestWriteQuotedKeyNames(t *testing.T) {
+	m := New(Options{})
+	sec := m.NewSection("s")
+	for _, name := range []string{"[x]", "-", "a=b"} {
+		sec.NewKey(name, "v")
+	}
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := LoadSources(Options{}, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyNames := func(sec *Section) (names []string) {
+		for name := range sec.All() {
+			names = append(names, name)
+		}
+		return names
+	}
+	if got, want := keyNames(back.Section("s")), keyNames(sec); !slices.Equal(got, want) {
+		t.Errorf("keys = %q, want %q\n%s", got, want, buf.String())
+	}
+}
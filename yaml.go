@@ -0,0 +1,77 @@
+package ini
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// yamlPlain matches strings which can be written as plain YAML scalars.
+	yamlPlain = regexp.MustCompile(`^[A-Za-z0-9_./][A-Za-z0-9_ ./@+-]*$`)
+	// yamlTimestamp matches strings read as timestamps, e.g. 2024-01-01.
+	yamlTimestamp = regexp.MustCompile(`^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}([Tt ]|$)`)
+	// yamlSexagesimal matches strings read as base 60 numbers by YAML 1.1, e.g. 12:30:00.
+	yamlSexagesimal = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+)
+
+// ToYAML returns all values encoded as YAML, keys of the default section are placed
+// at the top level and other sections are placed under their full names, child sections
+// are not nested unlike Provider.Read. Sections and keys are written in order of definition,
+// values of sensitive keys are redacted, see Key.SetSensitive.
+func (m *Manager) ToYAML() ([]byte, error) {
+	var buf bytes.Buffer
+	var sections []*Section
+	for sec := range m.All() {
+		if len(sec.name) > 0 {
+			sections = append(sections, sec)
+			continue
+		}
+		for name, key := range sec.All() {
+			// Sections take precedence over keys of the default section with the same name.
+			if m.HasSection(name) {
+				continue
+			}
+			buf.WriteString(yamlScalar(name) + ": " + yamlScalar(key.redacted(key.expand())) + "\n")
+		}
+	}
+
+	for _, sec := range sections {
+		buf.WriteString(yamlScalar(sec.Name()) + ":")
+		if len(sec.Keys()) == 0 {
+			buf.WriteString(" {}\n")
+			continue
+		}
+		buf.WriteByte('\n')
+		for name, key := range sec.All() {
+			buf.WriteString("  " + yamlScalar(name) + ": " + yamlScalar(key.redacted(key.expand())) + "\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlScalar returns s as a YAML string scalar, it is quoted unless it
+// would be read back as the same string.
+func yamlScalar(s string) string {
+	if yamlPlain.MatchString(s) && !strings.HasSuffix(s, " ") && !yamlTyped(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// yamlTyped returns true if plain scalar s would be read as a non-string value.
+func yamlTyped(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~", ".inf", ".nan", "-.inf", "+.inf":
+		return true
+	}
+	if yamlTimestamp.MatchString(s) || yamlSexagesimal.MatchString(s) {
+		return true
+	}
+	if _, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64); err == nil {
+		return true
+	}
+	_, err := strconv.ParseInt(s, 0, 64)
+	return err == nil
+}
@@ -0,0 +1,22 @@
+package ini
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToYAMLQuotesTyped(t *testing.T) {
+	m, err := LoadSources(Options{}, []byte("date = 2024-01-01\ntime = 12:30:00\nstamp = 2024-01-01 10:00:00\nname = plain\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := m.ToYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`date: "2024-01-01"`, `time: "12:30:00"`, `stamp: "2024-01-01 10:00:00"`, "name: plain"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("missing %s in:\n%s", want, data)
+		}
+	}
+}